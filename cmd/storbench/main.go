@@ -0,0 +1,103 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Command storbench runs a benchmark workload against a STOR server.
+//
+// Usage:
+//
+//	STOR_API_KEY=... storbench -host http://localhost:8000 -bucket bench -duration 1m -concurrency 16
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/cfichtmueller/stor-go-client/stor"
+	"github.com/cfichtmueller/stor-go-client/storbench"
+)
+
+func main() {
+	var cfg storbench.Config
+	host := flag.String("host", "http://localhost:8000", "URL of the STOR server")
+	flag.StringVar(&cfg.Bucket, "bucket", "", "bucket to run the benchmark in")
+	flag.StringVar(&cfg.Prefix, "prefix", "storbench/", "key prefix of written objects")
+	flag.DurationVar(&cfg.Duration, "duration", 0, "duration of the benchmark (default 30s)")
+	flag.IntVar(&cfg.Concurrency, "concurrency", 0, "number of concurrent workers (default 8)")
+	mix := flag.String("mix", "70:20:10", "read:write:list operation weights")
+	sizes := flag.String("sizes", "65536", "comma separated object sizes in bytes, optionally weighted as size:weight")
+	flag.IntVar(&cfg.Seed, "seed", 0, "number of objects written before the benchmark (default 16)")
+	flag.BoolVar(&cfg.Cleanup, "cleanup", true, "delete written objects afterwards")
+	flag.Parse()
+
+	if err := run(*host, *mix, *sizes, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "storbench: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(host, mix, sizes string, cfg storbench.Config) error {
+	if cfg.Bucket == "" {
+		return fmt.Errorf("-bucket is required")
+	}
+	var err error
+	if cfg.Mix, err = parseMix(mix); err != nil {
+		return err
+	}
+	if cfg.Sizes, err = parseSizes(sizes); err != nil {
+		return err
+	}
+	opts := stor.NewClientOptions().SetHost(host).SetApiKey(os.Getenv("STOR_API_KEY"))
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := storbench.Run(ctx, stor.NewClient(opts), cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("duration: %v\n", report.Duration.Round(1e6))
+	return report.Print(os.Stdout)
+}
+
+func parseMix(s string) (storbench.Mix, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return storbench.Mix{}, fmt.Errorf("invalid -mix %q, expected read:write:list", s)
+	}
+	weights := make([]int, 3)
+	for i, p := range parts {
+		w, err := strconv.Atoi(p)
+		if err != nil || w < 0 {
+			return storbench.Mix{}, fmt.Errorf("invalid -mix %q, expected read:write:list", s)
+		}
+		weights[i] = w
+	}
+	return storbench.Mix{Read: weights[0], Write: weights[1], List: weights[2]}, nil
+}
+
+func parseSizes(s string) ([]storbench.SizeClass, error) {
+	classes := make([]storbench.SizeClass, 0)
+	for _, p := range strings.Split(s, ",") {
+		sizeSpec, weightSpec, weighted := strings.Cut(p, ":")
+		size, err := strconv.ParseInt(sizeSpec, 10, 64)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid size %q", p)
+		}
+		weight := 1
+		if weighted {
+			if weight, err = strconv.Atoi(weightSpec); err != nil || weight < 0 {
+				return nil, fmt.Errorf("invalid size weight %q", p)
+			}
+		}
+		classes = append(classes, storbench.SizeClass{Size: size, Weight: weight})
+	}
+	return classes, nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AppendLogger is an io.WriteCloser for log and event shipping. Writes are buffered and appended to the
+// current segment object in the background every flush interval.
+//
+// Segment keys are derived from a key template. Every "{time:layout}" placeholder is replaced with the time of
+// the flush in UTC, formatted with time.Time.Format, so "logs/app1/{time:2006-01-02/15}.log" rolls to a new object
+// every hour. The rest of the template is used verbatim. The placeholder "{n}" is replaced with the number of the
+// segment within the period, which is incremented whenever a segment reaches the maximum size; without it,
+// segments are only rolled by time. A single Write is never split across segments.
+//
+// Buffered data is limited to the maximum segment size, or to DefaultAppendBufferSize if segments are not limited.
+// Writes that would exceed the limit, e.g. while flushes keep failing, are rejected with ErrAppendBufferFull.
+type AppendLogger struct {
+	client        *Client
+	bucket        string
+	keyTemplate   string
+	maxSize       int64
+	flushInterval time.Duration
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	err    error
+	closed bool
+
+	// segment state, only accessed while flushing
+	flushMu sync.Mutex
+	period  string
+	seq     int
+	offset  int64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// ErrAppendBufferFull is returned by AppendLogger.Write if the buffered data would exceed its limit.
+var ErrAppendBufferFull = fmt.Errorf("append logger buffer full")
+
+// DefaultAppendBufferSize is the limit of the data buffered by an AppendLogger whose segments are not limited.
+const DefaultAppendBufferSize = 64 << 20
+
+// NewAppendLogger creates an AppendLogger and starts flushing in the background.
+// If maxSize is 0, segments are not limited in size.
+func (c *Client) NewAppendLogger(bucket, keyTemplate string, flushInterval time.Duration, maxSize int64) *AppendLogger {
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+	l := &AppendLogger{
+		client:        c,
+		bucket:        bucket,
+		keyTemplate:   keyTemplate,
+		maxSize:       maxSize,
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}
+	l.wg.Add(1)
+	go l.run()
+	return l
+}
+
+// Write buffers p. It returns the error of the last failed flush, if any; buffered data is retried
+// with the next flush. If buffering p would exceed the limit, nothing is written and ErrAppendBufferFull
+// is returned.
+func (l *AppendLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, fmt.Errorf("write to closed append logger")
+	}
+	if int64(l.buf.Len()+len(p)) > l.bufferLimit() {
+		if l.err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrAppendBufferFull, l.err)
+		}
+		return 0, ErrAppendBufferFull
+	}
+	l.buf.Write(p)
+	return len(p), l.err
+}
+
+func (l *AppendLogger) bufferLimit() int64 {
+	if l.maxSize > 0 {
+		return l.maxSize
+	}
+	return DefaultAppendBufferSize
+}
+
+// Flush appends the buffered data to the current segment.
+func (l *AppendLogger) Flush(ctx context.Context) error {
+	l.flushMu.Lock()
+	defer l.flushMu.Unlock()
+
+	l.mu.Lock()
+	data := make([]byte, l.buf.Len())
+	copy(data, l.buf.Bytes())
+	l.mu.Unlock()
+	if len(data) == 0 {
+		return nil
+	}
+
+	err := l.appendSegment(ctx, data)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.err = err
+	if err == nil {
+		l.buf.Next(len(data))
+	}
+	return err
+}
+
+// Close stops the background flushing and flushes the remaining data.
+func (l *AppendLogger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	close(l.done)
+	l.wg.Wait()
+	return l.Flush(context.Background())
+}
+
+func (l *AppendLogger) run() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			if err := l.Flush(context.Background()); err != nil {
+				l.client.logger.Printf("stor: unable to flush append logger bucket=%s: %v", l.bucket, err)
+			}
+		}
+	}
+}
+
+// appendSegment appends data to the current segment, rolling to a new segment if necessary.
+func (l *AppendLogger) appendSegment(ctx context.Context, data []byte) error {
+	period := formatKeyTemplate(l.keyTemplate, time.Now().UTC())
+	if period != l.period {
+		l.period = period
+		l.seq = 0
+		l.offset = 0
+	} else if l.maxSize > 0 && l.offset > 0 && l.offset+int64(len(data)) > l.maxSize {
+		l.seq++
+		l.offset = 0
+	}
+
+	resumed := false
+	for {
+		key := strings.ReplaceAll(l.period, "{n}", strconv.Itoa(l.seq))
+		res, err := l.client.AppendObject(ctx, AppendObjectCommand{
+			Bucket: l.bucket,
+			Key:    key,
+			Offset: l.offset,
+			Data:   bytes.NewReader(data),
+		})
+		if errors.Is(err, ErrPreconditionFailed) && l.offset == 0 && strings.Contains(l.period, "{n}") {
+			// skip segments left behind by earlier runs
+			l.seq++
+			continue
+		}
+		if errors.Is(err, ErrPreconditionFailed) && !resumed {
+			// continue the segment at its current size
+			head, headErr := l.client.HeadObject(ctx, HeadObjectCommand{Bucket: l.bucket, Key: key})
+			if headErr != nil {
+				return headErr
+			}
+			l.offset = head.Size
+			resumed = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		l.offset = res.NextOffset
+		return nil
+	}
+}
+
+// formatKeyTemplate replaces every "{time:layout}" placeholder in template with t formatted with the layout.
+func formatKeyTemplate(template string, t time.Time) string {
+	const prefix = "{time:"
+	var b strings.Builder
+	for {
+		start := strings.Index(template, prefix)
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(template[:start])
+		b.WriteString(t.Format(template[start+len(prefix) : start+end]))
+		template = template[start+end+1:]
+	}
+	b.WriteString(template)
+	return b.String()
+}
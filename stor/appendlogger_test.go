@@ -0,0 +1,29 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatKeyTemplate(t *testing.T) {
+	at := time.Date(2024, 3, 7, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"logs/app1/{time:2006-01-02/15}.log", "logs/app1/2024-03-07/15.log"},
+		{"logs/Mon-Jan-PM-07/{time:2006}/{n}.log", "logs/Mon-Jan-PM-07/2024/{n}.log"},
+		{"{time:2006}/{time:01}/events", "2024/03/events"},
+		{"logs/static.log", "logs/static.log"},
+		{"logs/{time:2006", "logs/{time:2006"},
+	}
+	for _, tt := range tests {
+		if got := formatKeyTemplate(tt.template, at); got != tt.want {
+			t.Errorf("formatKeyTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
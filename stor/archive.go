@@ -9,8 +9,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"strings"
+	"time"
 )
 
 var (
@@ -20,6 +24,7 @@ var (
 	ArchiveStateComplete   = "complete"
 	ArchiveStateFailed     = "failed"
 	ErrArchiveNotFound     = fmt.Errorf("archive not found")
+	ErrArchiveFailed       = fmt.Errorf("archive failed")
 )
 
 type CreateArchiveCommand struct {
@@ -29,17 +34,32 @@ type CreateArchiveCommand struct {
 }
 
 type CreateArchiveResult struct {
-	Bucket    string
-	Key       string
-	ArchiveId string
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+	ArchiveId string `json:"archiveId"`
+}
+
+func (r *CreateArchiveResult) UnmarshalJSON(data []byte) error {
+	return unmarshalStringFields(data, map[string]*string{
+		"bucket":    &r.Bucket,
+		"key":       &r.Key,
+		"archiveid": &r.ArchiveId,
+	})
 }
 
 // CreateArchive creates an archive.
 func (c *Client) CreateArchive(ctx context.Context, cmd CreateArchiveCommand) (*CreateArchiveResult, error) {
+	if err := c.requireFeature(ctx, FeatureArchives); err != nil {
+		return nil, err
+	}
+	if err := c.limits.validateKey(cmd.Key); err != nil {
+		return nil, err
+	}
 	query := url.Values{}
 	query.Set("archives", "")
 	query.Set("type", cmd.Type)
 	res, body, err := c.doReq(ctx, R{
+		op:     OperationCreateArchive,
 		method: "POST",
 		path:   objectPath(cmd.Bucket, cmd.Key),
 		query:  query,
@@ -53,7 +73,7 @@ func (c *Client) CreateArchive(ctx context.Context, cmd CreateArchiveCommand) (*
 	}
 
 	var result CreateArchiveResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
 		return nil, err
 	}
 
@@ -75,11 +95,22 @@ type AddArchiveEntriesCommand struct {
 }
 
 type addArchiveEntriesRequest struct {
-	Entries []ArchiveEntry
+	Entries []ArchiveEntry `json:"entries"`
 }
 
 // UploadPart uploads a part in a multipart upload.
 func (c *Client) AddArchiveEntries(ctx context.Context, cmd AddArchiveEntriesCommand) error {
+	if err := c.requireFeature(ctx, FeatureArchives); err != nil {
+		return err
+	}
+	for _, e := range cmd.Entries {
+		if err := c.limits.validateKey(e.Key); err != nil {
+			return err
+		}
+		if err := c.tenantPolicy.checkKey(e.Key); err != nil {
+			return err
+		}
+	}
 	query := url.Values{}
 	query.Set("archive-id", cmd.ArchiveId)
 	body, err := json.Marshal(addArchiveEntriesRequest{Entries: cmd.Entries})
@@ -87,6 +118,7 @@ func (c *Client) AddArchiveEntries(ctx context.Context, cmd AddArchiveEntriesCom
 		return err
 	}
 	res, _, err := c.doReq(ctx, R{
+		op:          OperationAddArchiveEntries,
 		method:      "PUT",
 		path:        objectPath(cmd.Bucket, cmd.Key),
 		query:       query,
@@ -104,26 +136,123 @@ func (c *Client) AddArchiveEntries(ctx context.Context, cmd AddArchiveEntriesCom
 	return nil
 }
 
+// ArchiveNaming derives the name of an archive entry from the key of an object.
+// The rules are applied in order: StripPrefix, Flatten, Folder.
+type ArchiveNaming struct {
+	// StripPrefix removes the listed prefix from the key.
+	StripPrefix bool
+	// Flatten drops all folders and keeps only the last path segment of the key.
+	Flatten bool
+	// Folder places the entry in the given folder of the archive.
+	Folder string
+}
+
+func (n ArchiveNaming) name(prefix, key string) string {
+	name := key
+	if n.StripPrefix {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	if n.Flatten {
+		name = path.Base(name)
+	}
+	if n.Folder != "" {
+		name = path.Join(n.Folder, name)
+	}
+	return name
+}
+
+type AddArchiveEntriesByPrefixCommand struct {
+	Bucket    string
+	Key       string
+	ArchiveId string
+	// SourceBucket is the bucket to list. Defaults to Bucket.
+	SourceBucket string
+	// Prefix selects the objects to add.
+	Prefix string
+	Naming ArchiveNaming
+}
+
+// AddArchiveEntriesByPrefix adds all objects below a prefix to an archive, deriving entry names with cmd.Naming.
+// Folder markers are skipped. It returns the number of added entries.
+func (c *Client) AddArchiveEntriesByPrefix(ctx context.Context, cmd AddArchiveEntriesByPrefixCommand) (int, error) {
+	sourceBucket := cmd.SourceBucket
+	if sourceBucket == "" {
+		sourceBucket = cmd.Bucket
+	}
+	added := 0
+	p := NewListObjectsPaginator(c, ListObjectsCommand{
+		Bucket: sourceBucket,
+		Prefix: cmd.Prefix,
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return added, err
+		}
+		entries := make([]ArchiveEntry, 0, len(page.Objects))
+		for _, o := range page.Objects {
+			if strings.HasSuffix(o.Key, "/") {
+				continue
+			}
+			name := cmd.Naming.name(cmd.Prefix, o.Key)
+			if name == "" || name == "." {
+				return added, fmt.Errorf("%w: empty entry name for key %q", ErrInvalidArgument, o.Key)
+			}
+			entries = append(entries, ArchiveEntry{Key: o.Key, Name: name})
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		if err := c.AddArchiveEntries(ctx, AddArchiveEntriesCommand{
+			Bucket:    cmd.Bucket,
+			Key:       cmd.Key,
+			ArchiveId: cmd.ArchiveId,
+			Entries:   entries,
+		}); err != nil {
+			return added, err
+		}
+		added += len(entries)
+	}
+	return added, nil
+}
+
 type CompleteArchiveCommand struct {
 	Bucket    string
 	Key       string
 	ArchiveId string
 	// IfNoneMatch creates the archive only if the object key name does not already exist in the bucket
 	IfNoneMatch bool
+	// ContentType is the content type of the resulting archive object. Defaults to the server's choice for the archive type.
+	ContentType string
+	// ContentDisposition is served with downloads of the archive, e.g. `attachment; filename="export.zip"`.
+	ContentDisposition string
+	// CacheControl is served with downloads of the archive.
+	CacheControl string
 }
 
 func (c *Client) CompleteArchive(ctx context.Context, cmd CompleteArchiveCommand) error {
+	if err := c.requireFeature(ctx, FeatureArchives); err != nil {
+		return err
+	}
 	query := url.Values{}
 	query.Set("archive-id", cmd.ArchiveId)
 	header := http.Header{}
 	if cmd.IfNoneMatch {
 		header.Set("If-None-Match", "*")
 	}
+	if cmd.ContentDisposition != "" {
+		header.Set("Content-Disposition", cmd.ContentDisposition)
+	}
+	if cmd.CacheControl != "" {
+		header.Set("Cache-Control", cmd.CacheControl)
+	}
 	res, _, err := c.doReq(ctx, R{
-		method: "POST",
-		path:   objectPath(cmd.Bucket, cmd.Key),
-		query:  query,
-		header: header,
+		op:          OperationCompleteArchive,
+		method:      "POST",
+		path:        objectPath(cmd.Bucket, cmd.Key),
+		query:       query,
+		header:      header,
+		contentType: cmd.ContentType,
 	})
 	if err != nil {
 		return err
@@ -143,9 +272,13 @@ type AbortArchiveCommand struct {
 }
 
 func (c *Client) AbortArchive(ctx context.Context, cmd AbortArchiveCommand) error {
+	if err := c.requireFeature(ctx, FeatureArchives); err != nil {
+		return err
+	}
 	query := url.Values{}
 	query.Set("archive-id", cmd.ArchiveId)
 	res, _, err := c.doReq(ctx, R{
+		op:     OperationAbortArchive,
 		method: "DELETE",
 		path:   objectPath(cmd.Bucket, cmd.Key),
 		query:  query,
@@ -173,9 +306,13 @@ type GetArchiveResult struct {
 }
 
 func (c *Client) GetArchive(ctx context.Context, cmd GetArchiveCommand) (*GetArchiveResult, error) {
+	if err := c.requireFeature(ctx, FeatureArchives); err != nil {
+		return nil, err
+	}
 	query := url.Values{}
 	query.Set("archive-id", cmd.ArchiveId)
 	res, body, err := c.doReq(ctx, R{
+		op:     OperationGetArchive,
 		method: "GET",
 		path:   objectPath(cmd.Bucket, cmd.Key),
 		query:  query,
@@ -191,9 +328,96 @@ func (c *Client) GetArchive(ctx context.Context, cmd GetArchiveCommand) (*GetArc
 	}
 
 	var result GetArchiveResult
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal server response: %v", err)
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
+
+type DownloadArchiveCommand struct {
+	Bucket    string
+	Key       string
+	ArchiveId string
+	// PartSize is the size of the ranges that are downloaded concurrently. Defaults to 8 MB.
+	PartSize int64
+	// Concurrency is the number of concurrent range requests. Defaults to 4.
+	Concurrency int
+	// Offset resumes a previous download at the given offset, usually DownloadArchiveResult.ResumeOffset.
+	// IfMatch must be set as well, so that the resumed ranges belong to the same archive.
+	Offset int64
+	// IfMatch downloads the archive only if its ETag matches, usually DownloadArchiveResult.ETag of the download
+	// that is resumed. Otherwise, ErrPreconditionFailed is returned.
+	IfMatch string
+}
+
+type DownloadArchiveResult struct {
+	// Size is the size of the archive.
+	Size int64
+	// ETag is the ETag of the downloaded archive. It is empty if no response was received.
+	ETag string
+	// ResumeOffset is the offset up to which the archive has been written without gaps.
+	// If the download fails, it can be resumed from this offset.
+	ResumeOffset int64
+}
+
+// DownloadArchive waits for the archive to complete and downloads it into w using concurrent range requests.
+// If the archive fails, ErrArchiveFailed is returned.
+// The result is returned even if the download fails, so that the download can be resumed.
+func (c *Client) DownloadArchive(ctx context.Context, cmd DownloadArchiveCommand, w io.WriterAt) (*DownloadArchiveResult, error) {
+	result := &DownloadArchiveResult{ResumeOffset: cmd.Offset, ETag: cmd.IfMatch}
+	if cmd.Offset > 0 && cmd.IfMatch == "" {
+		return result, fmt.Errorf("%w: resuming a download at an offset requires IfMatch", ErrInvalidArgument)
+	}
+	if err := c.waitForArchive(ctx, GetArchiveCommand{
+		Bucket:    cmd.Bucket,
+		Key:       cmd.Key,
+		ArchiveId: cmd.ArchiveId,
+	}); err != nil {
+		return result, err
+	}
+
+	d := &rangeDownload{
+		client:      c,
+		bucket:      cmd.Bucket,
+		key:         cmd.Key,
+		w:           w,
+		offset:      cmd.Offset,
+		partSize:    cmd.PartSize,
+		concurrency: cmd.Concurrency,
+		etag:        cmd.IfMatch,
+	}
+	size, err := d.run(ctx)
+	result.Size = size
+	result.ETag = d.etag
+	result.ResumeOffset = d.resumeOffset()
+	return result, err
+}
+
+// waitForArchive polls the archive until it is complete.
+func (c *Client) waitForArchive(ctx context.Context, cmd GetArchiveCommand) error {
+	interval := time.Second
+	for {
+		archive, err := c.GetArchive(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		switch archive.State {
+		case ArchiveStateComplete:
+			return nil
+		case ArchiveStateFailed:
+			return ErrArchiveFailed
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if interval < 30*time.Second {
+			interval *= 2
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ObjectAttribute selects an attribute returned by GetObjectAttributes.
+type ObjectAttribute string
+
+const (
+	ObjectAttributeETag         ObjectAttribute = "etag"
+	ObjectAttributeChecksum     ObjectAttribute = "checksum"
+	ObjectAttributeSize         ObjectAttribute = "size"
+	ObjectAttributePartCount    ObjectAttribute = "partCount"
+	ObjectAttributeStorageClass ObjectAttribute = "storageClass"
+)
+
+type GetObjectAttributesCommand struct {
+	Bucket string
+	Key    string
+	// Attributes selects the attributes to return. If empty, all attributes are returned.
+	Attributes []ObjectAttribute
+}
+
+type GetObjectAttributesResult struct {
+	ETag              string `json:"etag,omitempty"`
+	Checksum          string `json:"checksum,omitempty"`
+	ChecksumAlgorithm string `json:"checksumAlgorithm,omitempty"`
+	Size              int64  `json:"size,omitempty"`
+	// PartCount is the number of parts of a multipart object. It is 0 for objects that were uploaded in one piece.
+	PartCount    int    `json:"partCount,omitempty"`
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// GetObjectAttributes returns the selected attributes of an object in a single request.
+// If the object cannot be found, the method returns ErrObjectNotFound.
+func (c *Client) GetObjectAttributes(ctx context.Context, cmd GetObjectAttributesCommand) (*GetObjectAttributesResult, error) {
+	query := url.Values{}
+	query.Set("attributes", "")
+	if len(cmd.Attributes) > 0 {
+		attributes := make([]string, len(cmd.Attributes))
+		for i, a := range cmd.Attributes {
+			attributes[i] = string(a)
+		}
+		query.Set("attributes", strings.Join(attributes, ","))
+	}
+	res, body, err := c.doReq(ctx, R{
+		op:    OperationGetObjectAttributes,
+		path:  objectPath(cmd.Bucket, cmd.Key),
+		query: query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == 404 {
+		return nil, ErrObjectNotFound
+	}
+	if res.StatusCode != 200 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to get object attributes: %v", res.StatusCode)
+	}
+
+	var result GetObjectAttributesResult
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
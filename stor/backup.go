@@ -0,0 +1,94 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BackupDestination is the location backups are written to.
+type BackupDestination struct {
+	Bucket string
+	// Prefix is prepended to the keys of the copied objects and the manifest.
+	Prefix string
+}
+
+type BackupManifest struct {
+	SourceBucket string    `json:"sourceBucket"`
+	Since        time.Time `json:"since"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Objects      []*Object `json:"objects"`
+}
+
+type BackupResult struct {
+	// ManifestKey is the key of the manifest in the destination bucket.
+	ManifestKey string
+	Manifest    BackupManifest
+}
+
+// BackupSince copies all objects of a bucket that have been created or modified since the given time to dest.
+// A manifest listing the copied objects is written to dest as "<prefix>manifest-<timestamp>.json".
+// When backing up into the source bucket, objects below the destination prefix are skipped.
+func (c *Client) BackupSince(ctx context.Context, bucket string, since time.Time, dest BackupDestination) (*BackupResult, error) {
+	manifest := BackupManifest{
+		SourceBucket: bucket,
+		Since:        since,
+		CreatedAt:    time.Now().UTC(),
+		Objects:      make([]*Object, 0),
+	}
+
+	err := c.walkObjects(ctx, ListObjectsCommand{Bucket: bucket}, func(page *ListObjectsResult) error {
+		for _, o := range page.Objects {
+			if o.CreatedAt.Before(since) {
+				continue
+			}
+			if dest.Bucket == bucket && dest.Prefix != "" && strings.HasPrefix(o.Key, dest.Prefix) {
+				continue
+			}
+			if err := c.backupObject(ctx, bucket, o.Key, dest); err != nil {
+				return fmt.Errorf("unable to back up object %s: %w", o.Key, err)
+			}
+			manifest.Objects = append(manifest.Objects, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	manifestKey := dest.Prefix + "manifest-" + manifest.CreatedAt.Format("20060102T150405Z") + ".json"
+	if _, err := c.CreateObject(ctx, CreateObjectCommand{
+		Bucket:      dest.Bucket,
+		Key:         manifestKey,
+		ContentType: "application/json",
+		Data:        bytes.NewReader(data),
+	}); err != nil {
+		return nil, fmt.Errorf("unable to write backup manifest: %w", err)
+	}
+
+	return &BackupResult{
+		ManifestKey: manifestKey,
+		Manifest:    manifest,
+	}, nil
+}
+
+func (c *Client) backupObject(ctx context.Context, bucket, key string, dest BackupDestination) error {
+	_, err := c.CopyObject(ctx, CopyObjectCommand{
+		Bucket:       dest.Bucket,
+		SourceBucket: bucket,
+		SourceKey:    key,
+		DestKey:      dest.Prefix + key,
+	})
+	return err
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MultiError is returned by batch operations if one or more tasks failed.
+type MultiError struct {
+	// Errors contains the errors of the failed tasks in task order, followed by the error of the context
+	// if tasks were not started because it was done.
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed tasks, so that errors.Is and errors.As match any of them
+// on Go 1.20 and later.
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// Is reports whether any of the task errors matches target. It makes errors.Is work on Go versions that do not
+// unwrap multiple errors.
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first task error that matches target. It makes errors.As work on Go versions that do not
+// unwrap multiple errors.
+func (e *MultiError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Batch runs many tasks with bounded concurrency.
+type Batch struct {
+	// Concurrency limits the number of concurrently running tasks. Defaults to 8.
+	Concurrency int
+	// StopOnError cancels the context of the remaining tasks after the first error.
+	StopOnError bool
+	// Logger receives the stack of panics in tasks. A panicking task fails with ErrInternalClient.
+	Logger Logger
+}
+
+// Run runs task for every index from 0 to n-1. It returns after all started tasks are done.
+// Tasks that have not been started when ctx is done are not run, which is reported once with the error of ctx.
+// Tasks skipped because of StopOnError are not reported.
+// If any task fails, a *MultiError is returned.
+func (b Batch) Run(parent context.Context, n int, task func(ctx context.Context, i int) error) error {
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	skipped := 0
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			skipped = n - i
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := b.runTask(ctx, i, task); err != nil {
+				errs[i] = err
+				if b.StopOnError {
+					cancel()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	failed := make([]error, 0)
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if err := parent.Err(); err != nil && skipped > 0 {
+		failed = append(failed, err)
+	}
+	if len(failed) > 0 {
+		return &MultiError{Errors: failed}
+	}
+	return nil
+}
+
+func (b Batch) runTask(ctx context.Context, i int, task func(ctx context.Context, i int) error) (err error) {
+	defer recoverPanic(b.Logger, &err)
+	return task(ctx, i)
+}
+
+// CopyObjects copies many objects concurrently. The results are in the order of the commands.
+// The result of a failed copy is nil.
+func (c *Client) CopyObjects(ctx context.Context, batch Batch, cmds []CopyObjectCommand) ([]*CreateObjectResult, error) {
+	results := make([]*CreateObjectResult, len(cmds))
+	if batch.Logger == nil {
+		batch.Logger = c.logger
+	}
+	err := batch.Run(ctx, len(cmds), func(ctx context.Context, i int) error {
+		res, err := c.CopyObject(ctx, cmds[i])
+		if err != nil {
+			return fmt.Errorf("unable to copy %s to %s: %w", cmds[i].SourceKey, cmds[i].DestKey, err)
+		}
+		results[i] = res
+		return nil
+	})
+	return results, err
+}
+
+// HeadObjectsEntry is the result of a single key of HeadObjects.
+type HeadObjectsEntry struct {
+	Key string
+	// Object is the metadata of the object. It is nil if the object was not found or the request failed.
+	Object *HeadObjectResult
+	// Err is ErrObjectNotFound for missing objects and the error of the request if it failed.
+	Err error
+}
+
+// HeadObjects fetches the metadata of many objects concurrently. The entries are in the order of the keys.
+// Missing objects are reported in their entry and are not treated as failures. If other requests fail,
+// a *MultiError is returned in addition to the entries.
+func (c *Client) HeadObjects(ctx context.Context, batch Batch, bucket string, keys []string) ([]HeadObjectsEntry, error) {
+	entries := make([]HeadObjectsEntry, len(keys))
+	for i, key := range keys {
+		entries[i].Key = key
+	}
+	if batch.Logger == nil {
+		batch.Logger = c.logger
+	}
+	err := batch.Run(ctx, len(keys), func(ctx context.Context, i int) error {
+		res, err := c.HeadObject(ctx, HeadObjectCommand{Bucket: bucket, Key: keys[i]})
+		if errors.Is(err, ErrObjectNotFound) {
+			entries[i].Err = err
+			return nil
+		}
+		if err != nil {
+			entries[i].Err = err
+			return fmt.Errorf("unable to head %s: %w", keys[i], err)
+		}
+		entries[i].Object = res
+		return nil
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// keys that were not requested because ctx is done
+		for i := range entries {
+			if entries[i].Object == nil && entries[i].Err == nil {
+				entries[i].Err = ctxErr
+			}
+		}
+	}
+	return entries, err
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMultiErrorIs(t *testing.T) {
+	err := error(&MultiError{Errors: []error{
+		fmt.Errorf("unable to head a: %w", ErrBucketNotFound),
+		fmt.Errorf("unable to head b: %w", ErrObjectNotFound),
+	}})
+	tests := []struct {
+		target error
+		want   bool
+	}{
+		{ErrBucketNotFound, true},
+		{ErrObjectNotFound, true},
+		{ErrPreconditionFailed, false},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(err, %v) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestMultiErrorAs(t *testing.T) {
+	uploadErr := &MultipartUploadError{UploadId: "upload", Err: ErrUploadNotFound}
+	err := error(&MultiError{Errors: []error{
+		errors.New("unrelated"),
+		fmt.Errorf("unable to upload: %w", uploadErr),
+	}})
+	var target *MultipartUploadError
+	if !errors.As(err, &target) || target != uploadErr {
+		t.Errorf("errors.As(err) = %v, want %v", target, uploadErr)
+	}
+}
+
+func TestBatchRunStopOnErrorReportsOnlyFailure(t *testing.T) {
+	failure := errors.New("failure")
+	err := Batch{Concurrency: 1, StopOnError: true}.Run(context.Background(), 10, func(ctx context.Context, i int) error {
+		if i == 2 {
+			return failure
+		}
+		return nil
+	})
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("Run = %v, want a *MultiError", err)
+	}
+	if len(multi.Errors) != 1 || multi.Errors[0] != failure {
+		t.Errorf("errors = %v, want only %v", multi.Errors, failure)
+	}
+}
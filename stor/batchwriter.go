@@ -0,0 +1,155 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"sync"
+)
+
+type BatchWriterOptions struct {
+	// Concurrency is the number of concurrent uploads. Defaults to 16.
+	Concurrency int
+	// QueueSize is the number of objects that are buffered before Put blocks. Defaults to 4 * Concurrency.
+	QueueSize int
+}
+
+// BatchWriter pipelines the upload of many small objects. Put copies the data into a pooled buffer and returns
+// immediately, while a fixed number of workers upload the queued objects.
+//
+// Objects without a content type get one derived from their extension, falling back to sniffing the content
+// of every object.
+type BatchWriter struct {
+	client *Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	bucket string
+	queue  chan *batchObject
+	wg     sync.WaitGroup
+
+	// queueMu is held for reading while sending to queue, so that Close cannot close it in between.
+	queueMu sync.RWMutex
+	closed  bool
+
+	mu   sync.Mutex
+	errs []error
+}
+
+type batchObject struct {
+	key         string
+	contentType string
+	buf         *bytes.Buffer
+}
+
+var batchBufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// NewBatchWriter creates a BatchWriter for bucket and starts its workers.
+// Canceling ctx stops the workers; objects that have not been uploaded yet fail with the context error.
+func (c *Client) NewBatchWriter(ctx context.Context, bucket string, opts BatchWriterOptions) *BatchWriter {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 16
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 4 * opts.Concurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	w := &BatchWriter{
+		client: c,
+		ctx:    ctx,
+		cancel: cancel,
+		bucket: bucket,
+		queue:  make(chan *batchObject, opts.QueueSize),
+	}
+	for i := 0; i < opts.Concurrency; i++ {
+		w.wg.Add(1)
+		go w.work()
+	}
+	return w
+}
+
+// Put queues an object for upload. The data is copied, so the caller may reuse it once Put returns.
+// If contentType is empty, it is derived from the key or the data.
+func (w *BatchWriter) Put(key string, data []byte, contentType string) error {
+	if contentType == "" {
+		contentType = detectObjectContentType(key, data)
+	}
+	w.queueMu.RLock()
+	defer w.queueMu.RUnlock()
+	if w.closed {
+		return fmt.Errorf("put to closed batch writer")
+	}
+	buf := batchBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	buf.Write(data)
+	select {
+	case w.queue <- &batchObject{key: key, contentType: contentType, buf: buf}:
+		return nil
+	case <-w.ctx.Done():
+		batchBufferPool.Put(buf)
+		return w.ctx.Err()
+	}
+}
+
+// Close waits until all queued objects have been uploaded. If any upload failed, a *MultiError is returned.
+func (w *BatchWriter) Close() error {
+	w.queueMu.Lock()
+	if w.closed {
+		w.queueMu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.queueMu.Unlock()
+
+	w.wg.Wait()
+	w.cancel()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.errs) > 0 {
+		return &MultiError{Errors: w.errs}
+	}
+	return nil
+}
+
+func (w *BatchWriter) work() {
+	defer w.wg.Done()
+	for o := range w.queue {
+		err := w.put(o)
+		batchBufferPool.Put(o.buf)
+		if err != nil {
+			w.mu.Lock()
+			w.errs = append(w.errs, fmt.Errorf("unable to put %s: %w", o.key, err))
+			w.mu.Unlock()
+		}
+	}
+}
+
+func (w *BatchWriter) put(o *batchObject) (err error) {
+	defer recoverPanic(w.client.logger, &err)
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	_, err = w.client.CreateObject(w.ctx, CreateObjectCommand{
+		Bucket:        w.bucket,
+		Key:           o.key,
+		ContentType:   o.contentType,
+		Data:          bytes.NewReader(o.buf.Bytes()),
+		ContentLength: int64(o.buf.Len()),
+	})
+	return err
+}
+
+// detectObjectContentType derives the content type from the extension of key and falls back to sniffing data.
+func detectObjectContentType(key string, data []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
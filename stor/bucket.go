@@ -6,7 +6,7 @@ package stor
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -20,8 +20,14 @@ type Bucket struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// MaxListBuckets is the maximum number of buckets that can be requested in a single ListBuckets call,
+// as configured on a default server.
+const MaxListBuckets = 1000
+
 type ListBucketsCommand struct {
 	StartAfter string
+	// MaxBuckets limits the results to max buckets. The server default is used if 0.
+	// Values outside of 0 to MaxListBuckets are rejected with ErrInvalidArgument.
 	MaxBuckets int
 }
 
@@ -30,7 +36,15 @@ type ListBucketsResult struct {
 	IsTruncated bool     `json:"isTruncated"`
 }
 
+// ListBuckets lists buckets. If the client was created with ClientOptions.SetBucketCacheTTL,
+// results are cached and invalidated by CreateBucket and DeleteBucket calls through the same client.
 func (c *Client) ListBuckets(ctx context.Context, cmd ListBucketsCommand) (*ListBucketsResult, error) {
+	if cmd.MaxBuckets < 0 || cmd.MaxBuckets > MaxListBuckets {
+		return nil, fmt.Errorf("%w: MaxBuckets must be between 0 and %d, got %d", ErrInvalidArgument, MaxListBuckets, cmd.MaxBuckets)
+	}
+	if cached, ok := c.bucketCache.get(cmd); ok {
+		return cached, nil
+	}
 	query := url.Values{}
 	if cmd.StartAfter != "" {
 		query.Set("start-after", cmd.StartAfter)
@@ -38,7 +52,10 @@ func (c *Client) ListBuckets(ctx context.Context, cmd ListBucketsCommand) (*List
 	if cmd.MaxBuckets != 0 {
 		query.Set("max-buckets", strconv.Itoa(cmd.MaxBuckets))
 	}
-	res, body, err := c.doReq(ctx, R{})
+	res, body, err := c.doReq(ctx, R{
+		op:    OperationListBuckets,
+		query: query,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -47,9 +64,10 @@ func (c *Client) ListBuckets(ctx context.Context, cmd ListBucketsCommand) (*List
 		return nil, fmt.Errorf("unable to list buckets: %v", res.StatusCode)
 	}
 	var listResult ListBucketsResult
-	if err := json.Unmarshal(body, &listResult); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal response: %v", err)
+	if err := c.unmarshalResponse(res, body, &listResult); err != nil {
+		return nil, err
 	}
+	c.bucketCache.put(cmd, &listResult)
 	return &listResult, nil
 }
 
@@ -59,30 +77,97 @@ type CreateBucketCommand struct {
 
 func (c *Client) CreateBucket(ctx context.Context, cmd CreateBucketCommand) (*Bucket, error) {
 	res, body, err := c.doReq(ctx, R{
+		op:     OperationCreateBucket,
 		method: "PUT",
 		path:   cmd.Name,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if res.StatusCode == 409 {
+		// the bucket may have been created by another client, so cached listings may lack it
+		c.bucketCache.invalidate()
+		return nil, ErrBucketAlreadyExists
+	}
 	if res.StatusCode != 201 {
 		//TODO: map error
 		return nil, fmt.Errorf("unable to create bucket: %v", res.StatusCode)
 	}
+	c.bucketCache.invalidate()
 	var bucket Bucket
-	if err := json.Unmarshal(body, &bucket); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal response: %v", err)
+	if err := c.unmarshalResponse(res, body, &bucket); err != nil {
+		return nil, err
 	}
 
 	return &bucket, nil
 }
 
+type EnsureBucketOptions struct {
+	// NoncePolicy is applied to the bucket if set, also if the bucket already existed.
+	NoncePolicy *NoncePolicy
+}
+
+// EnsureBucket creates a bucket unless it already exists and applies the configuration in opts.
+// It returns the bucket in both cases, so it can be called on every start of an application.
+//
+// The server does not support lifecycle rules, CORS or tags on buckets yet, so only nonce policies can be applied.
+func (c *Client) EnsureBucket(ctx context.Context, name string, opts EnsureBucketOptions) (*Bucket, error) {
+	if opts.NoncePolicy != nil {
+		if err := c.requireFeature(ctx, FeatureNoncePolicies); err != nil {
+			return nil, err
+		}
+	}
+	bucket, err := c.CreateBucket(ctx, CreateBucketCommand{Name: name})
+	if errors.Is(err, ErrBucketAlreadyExists) {
+		bucket, err = c.findBucket(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.NoncePolicy != nil {
+		if err := c.SetBucketNoncePolicy(ctx, name, *opts.NoncePolicy); err != nil {
+			return nil, err
+		}
+	}
+	return bucket, nil
+}
+
+// findBucket pages through the bucket listing until it finds the bucket with the given name.
+// If the bucket does not exist, the method returns ErrBucketNotFound.
+func (c *Client) findBucket(ctx context.Context, name string) (*Bucket, error) {
+	cmd := ListBucketsCommand{}
+	for {
+		page, err := c.ListBuckets(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page.Buckets {
+			if page.Buckets[i].Name == name {
+				return &page.Buckets[i], nil
+			}
+		}
+		if !page.IsTruncated || len(page.Buckets) == 0 {
+			return nil, ErrBucketNotFound
+		}
+		cmd.StartAfter = page.Buckets[len(page.Buckets)-1].Name
+	}
+}
+
+var ErrBucketNameMismatch = fmt.Errorf("confirmation does not match bucket name")
+
 type DeleteBucketCommand struct {
 	Name string
+	// ConfirmName must match Name if set. It is required if the client was created with
+	// ClientOptions.SetRequireDeleteConfirmation.
+	ConfirmName string
 }
 
 func (c *Client) DeleteBucket(ctx context.Context, cmd DeleteBucketCommand) error {
+	if (cmd.ConfirmName != "" || c.requireDeleteConfirmation) && cmd.ConfirmName != cmd.Name {
+		return fmt.Errorf("%w: unable to delete bucket %s", ErrBucketNameMismatch, cmd.Name)
+	}
 	res, _, err := c.doReq(ctx, R{
+		op:     OperationDeleteBucket,
 		method: "DELETE",
 		path:   cmd.Name,
 	})
@@ -93,5 +178,72 @@ func (c *Client) DeleteBucket(ctx context.Context, cmd DeleteBucketCommand) erro
 		//TODO: map error
 		return fmt.Errorf("unable to delete bucket: %v", res.StatusCode)
 	}
+	c.bucketCache.invalidate()
 	return nil
 }
+
+// PreDeleteReport lists the resources that prevent a bucket from being deleted.
+type PreDeleteReport struct {
+	// Objects is the number of objects in the bucket.
+	Objects int
+	// Size is the total size of the objects in bytes.
+	Size int64
+	// Uploads is the number of multipart uploads in progress.
+	Uploads int
+}
+
+// Empty reports whether no blocking resources were found.
+func (r *PreDeleteReport) Empty() bool {
+	return r.Objects == 0 && r.Uploads == 0
+}
+
+// PreDeleteReport reports the resources that would make DeleteBucket fail.
+//
+// The server does not yet allow listing archives or nonces, so only objects and multipart uploads are counted.
+func (c *Client) PreDeleteReport(ctx context.Context, bucket string) (*PreDeleteReport, error) {
+	report := &PreDeleteReport{}
+	err := c.walkObjects(ctx, ListObjectsCommand{
+		Bucket: bucket,
+	}, func(page *ListObjectsResult) error {
+		for _, o := range page.Objects {
+			report.Objects++
+			report.Size += o.Size
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = c.walkUploads(ctx, ListMultipartUploadsCommand{
+		Bucket: bucket,
+	}, func(page *ListMultipartUploadsResult) error {
+		report.Uploads += len(page.Uploads)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// BucketExists checks whether a bucket exists, requesting a single key of it.
+// Errors other than a missing bucket, e.g. transport errors, are returned as errors.
+// If the client was created with ClientOptions.SetBucketCacheTTL, results are cached like those of ListBuckets.
+func (c *Client) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	if exists, ok := c.bucketCache.getExists(bucket); ok {
+		return exists, nil
+	}
+	_, err := c.ListObjects(ctx, ListObjectsCommand{
+		Bucket:  bucket,
+		MaxKeys: 1,
+	})
+	if errors.Is(err, ErrBucketNotFound) {
+		c.bucketCache.putExists(bucket, false)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	c.bucketCache.putExists(bucket, true)
+	return true, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// BucketConfig is the desired configuration of a bucket.
+//
+// The server does not support versioning, lifecycle rules, CORS, tags or quotas as bucket settings yet,
+// so the nonce policy is the only setting besides the existence of the bucket.
+type BucketConfig struct {
+	Name string
+	// NoncePolicy is the desired nonce policy. If nil, the policy of the bucket is left unchanged.
+	NoncePolicy *NoncePolicy
+}
+
+// BucketChange describes a setting that was changed by ApplyBucketConfig.
+type BucketChange struct {
+	Setting string
+	From    string
+	To      string
+}
+
+type BucketChangeReport struct {
+	// Created reports whether the bucket had to be created.
+	Created bool
+	Changes []BucketChange
+}
+
+// Changed reports whether anything was changed.
+func (r *BucketChangeReport) Changed() bool {
+	return r.Created || len(r.Changes) > 0
+}
+
+// ApplyBucketConfig creates the bucket if it does not exist and applies the settings of desired that differ from
+// the actual configuration. Settings that already match are not written.
+func (c *Client) ApplyBucketConfig(ctx context.Context, desired BucketConfig) (*BucketChangeReport, error) {
+	if desired.NoncePolicy != nil {
+		if err := c.requireFeature(ctx, FeatureNoncePolicies); err != nil {
+			return nil, err
+		}
+	}
+	report := &BucketChangeReport{}
+	_, err := c.CreateBucket(ctx, CreateBucketCommand{Name: desired.Name})
+	if err == nil {
+		report.Created = true
+	} else if !errors.Is(err, ErrBucketAlreadyExists) {
+		return nil, err
+	}
+
+	if desired.NoncePolicy != nil {
+		actual, err := c.GetBucketNoncePolicy(ctx, desired.Name)
+		if err != nil {
+			return report, err
+		}
+		if !equalNoncePolicies(actual, desired.NoncePolicy) {
+			if err := c.SetBucketNoncePolicy(ctx, desired.Name, *desired.NoncePolicy); err != nil {
+				return report, err
+			}
+			report.Changes = append(report.Changes, BucketChange{
+				Setting: "noncePolicy",
+				From:    fmt.Sprintf("%+v", *actual),
+				To:      fmt.Sprintf("%+v", *desired.NoncePolicy),
+			})
+		}
+	}
+	return report, nil
+}
+
+// equalNoncePolicies compares two policies, treating a nil and an empty prefix list as equal.
+func equalNoncePolicies(a, b *NoncePolicy) bool {
+	if a.MaxTTL != b.MaxTTL {
+		return false
+	}
+	if len(a.AllowedPrefixes) == 0 && len(b.AllowedPrefixes) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.AllowedPrefixes, b.AllowedPrefixes)
+}
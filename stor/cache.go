@@ -0,0 +1,105 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"sync"
+	"time"
+)
+
+// bucketCache caches ListBuckets and BucketExists results for a short time.
+type bucketCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[ListBucketsCommand]bucketCacheEntry
+	exists  map[string]bucketExistsEntry
+}
+
+type bucketCacheEntry struct {
+	result    ListBucketsResult
+	expiresAt time.Time
+}
+
+type bucketExistsEntry struct {
+	exists    bool
+	expiresAt time.Time
+}
+
+func newBucketCache(ttl time.Duration) *bucketCache {
+	return &bucketCache{
+		ttl:     ttl,
+		entries: make(map[ListBucketsCommand]bucketCacheEntry),
+		exists:  make(map[string]bucketExistsEntry),
+	}
+}
+
+func (c *bucketCache) get(cmd ListBucketsCommand) (*ListBucketsResult, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[cmd]
+	if !ok || time.Now().After(e.expiresAt) {
+		delete(c.entries, cmd)
+		return nil, false
+	}
+	return copyListBucketsResult(e.result), true
+}
+
+func (c *bucketCache) put(cmd ListBucketsCommand, result *ListBucketsResult) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cmd] = bucketCacheEntry{
+		result:    *copyListBucketsResult(*result),
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *bucketCache) getExists(bucket string) (exists bool, ok bool) {
+	if c == nil {
+		return false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.exists[bucket]
+	if !ok || time.Now().After(e.expiresAt) {
+		delete(c.exists, bucket)
+		return false, false
+	}
+	return e.exists, true
+}
+
+func (c *bucketCache) putExists(bucket string, exists bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.exists[bucket] = bucketExistsEntry{
+		exists:    exists,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *bucketCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[ListBucketsCommand]bucketCacheEntry)
+	c.exists = make(map[string]bucketExistsEntry)
+}
+
+func copyListBucketsResult(r ListBucketsResult) *ListBucketsResult {
+	buckets := make([]Bucket, len(r.Buckets))
+	copy(buckets, r.Buckets)
+	r.Buckets = buckets
+	return &r
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestClient creates a client for a test server that serves handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewClient(NewClientOptions().SetHost(srv.URL))
+}
+
+// within fails the test if fn does not return within a second.
+func within(t *testing.T, fn func() error) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("operation did not return after the context was canceled")
+		return nil
+	}
+}
+
+func TestReadObjectCanceledDuringBody(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Length", "10")
+		w.Write([]byte("hello"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	res, err := client.ReadObject(ctx, "bucket", "key")
+	if err != nil {
+		t.Fatalf("ReadObject: %v", err)
+	}
+	defer res.Close()
+	buf := make([]byte, 5)
+	if n, err := res.Read(buf); err != nil || string(buf[:n]) != "hello" {
+		t.Fatalf("first read = %q, %v", buf[:n], err)
+	}
+
+	cancel()
+	err = within(t, func() error {
+		for {
+			if _, err := res.Read(buf); err != nil {
+				return err
+			}
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("read after cancel = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRequestCanceledWhileWaitingForResponse(t *testing.T) {
+	started := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	err := within(t, func() error {
+		_, err := client.ListObjects(ctx, ListObjectsCommand{Bucket: "bucket"})
+		return err
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ListObjects = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPaginatorCanceledBetweenPages(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"isTruncated":true,"objects":[{"key":"a"}]}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewListObjectsPaginator(client, ListObjectsCommand{Bucket: "bucket"})
+	if _, err := p.NextPage(ctx); err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	cancel()
+	if _, err := p.NextPage(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("page after cancel = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestDownloadArchiveCanceledWhileWaiting(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"archive","state":"pending","type":"zip"}`))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := within(t, func() error {
+		_, err := client.DownloadArchive(ctx, DownloadArchiveCommand{
+			Bucket:    "bucket",
+			Key:       "archive.zip",
+			ArchiveId: "archive",
+		}, nil)
+		return err
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DownloadArchive = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ContentAddressedPrefix is the key prefix of content addressed objects.
+const ContentAddressedPrefix = "cas/"
+
+type CreateContentAddressedObjectCommand struct {
+	Bucket      string
+	ContentType string
+	// Data is read twice: once to compute the hash and once to upload it.
+	Data io.ReadSeeker
+}
+
+type CreateContentAddressedObjectResult struct {
+	// Key is the key of the object, "cas/<sha256>".
+	Key string
+	// Skipped is true if the object already existed and was not uploaded.
+	Skipped bool
+	// ETag is the ETag of the uploaded object. It is empty if the upload was skipped.
+	ETag string
+}
+
+// CreateContentAddressedObject stores data under a key derived from its SHA-256 hash.
+// If an object with the key already exists, the upload is skipped.
+// Hashing is aborted when ctx is done.
+func (c *Client) CreateContentAddressedObject(ctx context.Context, cmd CreateContentAddressedObjectCommand) (*CreateContentAddressedObjectResult, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, &contextReader{ctx: ctx, r: cmd.Data}); err != nil {
+		return nil, fmt.Errorf("unable to hash data: %w", err)
+	}
+	if _, err := cmd.Data.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	result := &CreateContentAddressedObjectResult{
+		Key: ContentAddressedPrefix + hex.EncodeToString(h.Sum(nil)),
+	}
+
+	exists, err := c.ObjectExists(ctx, cmd.Bucket, result.Key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		result.Skipped = true
+		return result, nil
+	}
+
+	res, err := c.CreateObject(ctx, CreateObjectCommand{
+		Bucket:      cmd.Bucket,
+		Key:         result.Key,
+		ContentType: cmd.ContentType,
+		Data:        cmd.Data,
+		IfNoneMatch: true,
+	})
+	if errors.Is(err, ErrPreconditionFailed) {
+		// another client uploaded the same content in the meantime
+		result.Skipped = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.ETag = res.ETag
+	return result, nil
+}
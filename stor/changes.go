@@ -0,0 +1,96 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ChangeType is the kind of change of an object.
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// MaxListChanges is the maximum number of changes that can be requested in a single ListChanges call.
+const MaxListChanges = 1000
+
+type ListChangesCommand struct {
+	Bucket string
+	// Cursor continues the changelog after the changes that were already read, usually
+	// ListChangesResult.NextCursor of a previous call. If empty, the changelog is read from its start.
+	Cursor string
+	// MaxChanges limits the results to max changes. Defaults to MaxListChanges if 0.
+	MaxChanges int
+}
+
+type ObjectChange struct {
+	Key  string     `json:"key"`
+	Type ChangeType `json:"type"`
+	// ETag and Size describe the object after the change. They are empty for deleted objects.
+	ETag string `json:"etag,omitempty"`
+	Size int64  `json:"size,omitempty"`
+	// ChangedAt is the time of the change.
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type ListChangesResult struct {
+	Changes []*ObjectChange `json:"changes"`
+	// NextCursor is the cursor to continue with. It should be persisted once the changes have been processed.
+	NextCursor  string `json:"nextCursor"`
+	IsTruncated bool   `json:"isTruncated"`
+}
+
+// ListChanges returns the objects that were created, updated or deleted since the given cursor, in the order
+// of the changes. Callers read pages until IsTruncated is false and keep NextCursor for the next call,
+// so that indexers and caches do not have to rescan the bucket.
+// If the bucket does not exist, the method returns ErrBucketNotFound.
+func (c *Client) ListChanges(ctx context.Context, cmd ListChangesCommand) (*ListChangesResult, error) {
+	if err := c.requireFeature(ctx, FeatureChangelog); err != nil {
+		return nil, err
+	}
+	if cmd.MaxChanges < 0 || cmd.MaxChanges > MaxListChanges {
+		return nil, fmt.Errorf("%w: MaxChanges must be between 0 and %d, got %d", ErrInvalidArgument, MaxListChanges, cmd.MaxChanges)
+	}
+	query := url.Values{}
+	query.Set("changes", "")
+	if cmd.Cursor != "" {
+		query.Set("cursor", cmd.Cursor)
+	}
+	if cmd.MaxChanges > 0 {
+		query.Set("max-changes", strconv.Itoa(cmd.MaxChanges))
+	}
+	res, body, err := c.doReq(ctx, R{
+		op:    OperationListChanges,
+		path:  cmd.Bucket,
+		query: query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == 404 {
+		return nil, ErrBucketNotFound
+	}
+	if res.StatusCode == 410 {
+		return nil, fmt.Errorf("%w: cursor has expired", ErrInvalidArgument)
+	}
+	if res.StatusCode != 200 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to list changes: %v", res.StatusCode)
+	}
+
+	var result ListChangesResult
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
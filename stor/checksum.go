@@ -0,0 +1,194 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned if downloaded data does not match the checksum reported by the server.
+var ErrChecksumMismatch = fmt.Errorf("checksum mismatch")
+
+// ChecksumAlgorithm is an algorithm the client uses to compute the checksum of uploaded data.
+type ChecksumAlgorithm string
+
+const (
+	ChecksumCRC32  ChecksumAlgorithm = "CRC32"
+	ChecksumCRC32C ChecksumAlgorithm = "CRC32C"
+	ChecksumSHA1   ChecksumAlgorithm = "SHA1"
+	ChecksumSHA256 ChecksumAlgorithm = "SHA256"
+)
+
+const checksumAlgorithmHeader = "Stor-Checksum-Algorithm"
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+func (a ChecksumAlgorithm) newHash() (hash.Hash, error) {
+	switch a {
+	case ChecksumCRC32:
+		return crc32.NewIEEE(), nil
+	case ChecksumCRC32C:
+		return crc32.New(crc32cTable), nil
+	case ChecksumSHA1:
+		return sha1.New(), nil
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown checksum algorithm %q", ErrInvalidArgument, a)
+	}
+}
+
+// header returns the name of the header or trailer that carries the checksum.
+func (a ChecksumAlgorithm) header() string {
+	switch a {
+	case ChecksumCRC32:
+		return "Stor-Checksum-Crc32"
+	case ChecksumCRC32C:
+		return "Stor-Checksum-Crc32c"
+	case ChecksumSHA1:
+		return "Stor-Checksum-Sha1"
+	default:
+		return "Stor-Checksum-Sha256"
+	}
+}
+
+// checksumReader computes the checksum of the data read through it and stores it in the trailer
+// once the data has been read completely.
+type checksumReader struct {
+	r         io.Reader
+	hash      hash.Hash
+	algorithm ChecksumAlgorithm
+	trailer   http.Header
+	sum       string
+}
+
+// newChecksumReader wraps r and sets up the trailer for the checksum in header and trailer.
+func newChecksumReader(r io.Reader, algorithm ChecksumAlgorithm, header, trailer http.Header) (*checksumReader, error) {
+	h, err := algorithm.newHash()
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		r = http.NoBody
+	}
+	header.Set(checksumAlgorithmHeader, string(algorithm))
+	// the value is set once the body has been read
+	trailer.Set(algorithm.header(), "")
+	return &checksumReader{r: r, hash: h, algorithm: algorithm, trailer: trailer}, nil
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		r.sum = base64.StdEncoding.EncodeToString(r.hash.Sum(nil))
+		r.trailer.Set(r.algorithm.header(), r.sum)
+	}
+	return n, err
+}
+
+// compositeChecksum computes the checksum of a multipart object from the checksums of its parts: the hash of
+// the concatenated part checksums, followed by the number of parts, e.g. "q2Ct...=-3".
+func compositeChecksum(algorithm ChecksumAlgorithm, parts []PartReference) (string, error) {
+	h, err := algorithm.newHash()
+	if err != nil {
+		return "", err
+	}
+	sorted := make([]PartReference, len(parts))
+	copy(sorted, parts)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].PartNumber < sorted[j].PartNumber
+	})
+	for _, p := range sorted {
+		if p.Checksum == "" {
+			return "", fmt.Errorf("%w: part %d has no %s checksum", ErrInvalidArgument, p.PartNumber, algorithm)
+		}
+		sum, err := base64.StdEncoding.DecodeString(p.Checksum)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid checksum of part %d: %v", ErrInvalidArgument, p.PartNumber, err)
+		}
+		h.Write(sum)
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)) + "-" + strconv.Itoa(len(sorted)), nil
+}
+
+// expectedChecksum is the checksum of a complete object as reported by the server.
+type expectedChecksum struct {
+	name    string
+	newHash func() hash.Hash
+	encode  func([]byte) string
+	want    string
+}
+
+// expectedChecksumFromHeader returns the checksum reported in the response header, or nil if there is none.
+// Besides the checksum headers, only an explicit Content-MD5 is used. ETags are never treated as checksums.
+func expectedChecksumFromHeader(header http.Header) *expectedChecksum {
+	if a := ChecksumAlgorithm(header.Get(checksumAlgorithmHeader)); a != "" {
+		// composite checksums of multipart objects cannot be verified against the complete data
+		if want := header.Get(a.header()); want != "" && !strings.Contains(want, "-") {
+			if _, err := a.newHash(); err == nil {
+				return &expectedChecksum{
+					name: string(a),
+					newHash: func() hash.Hash {
+						h, _ := a.newHash()
+						return h
+					},
+					encode: base64.StdEncoding.EncodeToString,
+					want:   want,
+				}
+			}
+		}
+	}
+	// ETags are opaque, an ETag that looks like an MD5 digest may still be computed differently
+	if want := header.Get("Content-MD5"); want != "" {
+		return &expectedChecksum{
+			name:    "MD5",
+			newHash: md5.New,
+			encode:  base64.StdEncoding.EncodeToString,
+			want:    want,
+		}
+	}
+	return nil
+}
+
+func (e *expectedChecksum) verify(sum []byte) error {
+	if got := e.encode(sum); got != e.want {
+		return fmt.Errorf("%w: %s is %s, expected %s", ErrChecksumMismatch, e.name, got, e.want)
+	}
+	return nil
+}
+
+// verifyingReader verifies the checksum of the data read through it once it has been read completely.
+type verifyingReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected *expectedChecksum
+}
+
+func newVerifyingReader(r io.ReadCloser, expected *expectedChecksum) *verifyingReader {
+	return &verifyingReader{ReadCloser: r, hash: expected.newHash(), expected: expected}
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if verifyErr := r.expected.verify(r.hash.Sum(nil)); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestExpectedChecksumFromHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{"none", http.Header{}, ""},
+		{"md5 like etag", http.Header{"Etag": {`"9e107d9d372bb6826bd81d3542a419d6"`}}, ""},
+		{"content md5", http.Header{"Content-Md5": {"nhB9nTcrtoJr2B01QqQZ1g=="}}, "MD5"},
+		{"checksum", http.Header{
+			"Stor-Checksum-Algorithm": {"SHA256"},
+			"Stor-Checksum-Sha256":    {"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+		}, "SHA256"},
+		{"composite checksum", http.Header{
+			"Stor-Checksum-Algorithm": {"SHA256"},
+			"Stor-Checksum-Sha256":    {"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=-3"},
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if c := expectedChecksumFromHeader(tt.header); c != nil {
+				got = c.name
+			}
+			if got != tt.want {
+				t.Errorf("checksum = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,26 +9,52 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strconv"
 	"time"
 )
 
 type Client struct {
-	httpClient *http.Client
-	host       string
-	auth       string
+	httpClient                *http.Client
+	host                      string
+	auth                      string
+	logger                    Logger
+	slowRequestThreshold      time.Duration
+	hooks                     Hooks
+	limits                    Limits
+	timeout                   time.Duration
+	operationTimeouts         map[OperationName]time.Duration
+	deadlinePerMB             time.Duration
+	requireDeleteConfirmation bool
+	bucketCache               *bucketCache
+	serverVersion             int64
+	listRetries               int
+	origin                    OriginFunc
+	originBackfill            bool
+	tenantPolicy              *TenantPolicy
+	noncePolicies             *noncePolicyCache
+	strictResponses           bool
+}
+
+// Logger is used by the client to log messages. It is satisfied by *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
 }
 
 type R struct {
+	op            OperationName
 	method        string
 	path          string
 	query         url.Values
 	contentType   string
-	contentLength int
+	contentLength int64
 	body          io.Reader
 	header        http.Header
+	// trailer is sent after a chunked body, e.g. with a checksum computed while streaming.
+	trailer http.Header
 }
 
 // NewClient creates a new client to connect to a STOR server.
@@ -43,29 +69,62 @@ func NewClient(opts ...*ClientOptions) *Client {
 	}
 
 	client := &Client{
-		host:       opt.Host,
-		auth:       "Bearer " + opt.ApiKey,
-		httpClient: opt.HTTPCLient,
+		host:                 opt.Host,
+		auth:                 "Bearer " + opt.ApiKey,
+		httpClient:           opt.HTTPCLient,
+		logger:               opt.Logger,
+		slowRequestThreshold: opt.SlowRequestThreshold,
+		hooks:                opt.Hooks,
+		limits:               DefaultLimits(),
+	}
+
+	if opt.Limits != nil {
+		client.limits = opt.Limits.withDefaults()
+	}
+
+	if opt.DialOptions != nil {
+		client.httpClient = newDialer(*opt.DialOptions).httpClient(client.httpClient)
+	}
+
+	if client.logger == nil {
+		client.logger = log.Default()
 	}
 
 	if opt.Timeout != nil {
-		client.httpClient.Timeout = *opt.Timeout
+		client.timeout = *opt.Timeout
 	} else {
-		client.httpClient.Timeout = 30 * time.Second
+		client.timeout = 30 * time.Second
+	}
+	if len(opt.OperationTimeouts) > 0 {
+		client.operationTimeouts = make(map[OperationName]time.Duration, len(opt.OperationTimeouts))
+		for op, d := range opt.OperationTimeouts {
+			client.operationTimeouts[op] = d
+		}
+	}
+	client.deadlinePerMB = opt.DeadlinePerMB
+	client.requireDeleteConfirmation = opt.RequireDeleteConfirmation
+	client.tenantPolicy = opt.TenantPolicy
+	client.strictResponses = opt.StrictResponses
+	if opt.NoncePolicyTTL > 0 {
+		client.noncePolicies = newNoncePolicyCache(opt.NoncePolicyTTL)
+	}
+	client.origin = opt.Origin
+	client.originBackfill = opt.OriginBackfill
+	client.listRetries = 5
+	if opt.ListRetries != nil {
+		client.listRetries = *opt.ListRetries
+	}
+	if opt.BucketCacheTTL > 0 {
+		client.bucketCache = newBucketCache(opt.BucketCacheTTL)
 	}
 
 	return client
 }
 
-func (c *Client) newUrl() *url.URL {
-	u, err := url.Parse(c.host)
-	if err != nil {
-		panic(err)
-	}
-	return u
-}
-
 func (c *Client) createReq(ctx context.Context, r R) (*http.Request, error) {
+	if err := c.tenantPolicy.checkRequest(r); err != nil {
+		return nil, err
+	}
 	method := r.method
 	if method == "" {
 		method = "GET"
@@ -79,11 +138,20 @@ func (c *Client) createReq(ctx context.Context, r R) (*http.Request, error) {
 		return nil, err
 	}
 	req.Header.Add("Authorization", c.auth)
+	req.Header.Set(apiVersionHeader, strconv.Itoa(APIVersion))
 	if r.contentType != "" {
 		req.Header.Add("Content-Type", r.contentType)
 	}
 	if r.contentLength != 0 {
-		req.Header.Add("Content-Length", strconv.Itoa(r.contentLength))
+		req.ContentLength = r.contentLength
+	}
+	if r.trailer != nil {
+		// trailers require a chunked body, the server learns the size from the decoded length
+		if req.ContentLength > 0 {
+			req.Header.Set("Stor-Decoded-Content-Length", strconv.FormatInt(req.ContentLength, 10))
+		}
+		req.ContentLength = -1
+		req.Trailer = r.trailer
 	}
 
 	if r.header != nil {
@@ -97,29 +165,124 @@ func (c *Client) createReq(ctx context.Context, r R) (*http.Request, error) {
 	return req, nil
 }
 
-func (c *Client) doReq(ctx context.Context, r R) (*http.Response, []byte, error) {
+// requestBodySize returns the size of the request body, -1 if it is unknown.
+// Chunked bodies that carry trailers report their size in Stor-Decoded-Content-Length.
+func requestBodySize(req *http.Request) int64 {
+	if req.ContentLength >= 0 {
+		return req.ContentLength
+	}
+	size, err := strconv.ParseInt(req.Header.Get("Stor-Decoded-Content-Length"), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+func (c *Client) doReq(ctx context.Context, r R) (_ *http.Response, _ []byte, err error) {
+	defer recoverPanic(c.logger, &err)
+	tracer := c.newTracer()
+	if tracer != nil {
+		ctx = httptrace.WithClientTrace(ctx, tracer.clientTrace())
+	}
+	ctx, deadline := c.newDeadline(ctx, r.op)
+	defer deadline.stop()
 	req, err := c.createReq(ctx, r)
 	if err != nil {
 		return nil, nil, err
 	}
+	deadline.extend(requestBodySize(req))
 	res, err := c.httpClient.Do(req)
 	if err != nil {
+		err = deadline.wrap(err)
+		if tracer != nil {
+			c.requestDone(tracer, req.Method, r.path, 0, err)
+		}
 		return nil, nil, err
 	}
+	defer res.Body.Close()
 
+	c.recordServerVersion(res.Header)
+	deadline.extend(res.ContentLength)
 	b, err := io.ReadAll(res.Body)
+	err = deadline.wrap(err)
+	if tracer != nil {
+		c.requestDone(tracer, req.Method, r.path, res.StatusCode, err)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
 	return res, b, nil
 }
 
+// streamReq performs a request without consuming the response body.
+// Callers are expected to close the response body.
+func (c *Client) streamReq(ctx context.Context, r R) (_ *http.Response, err error) {
+	defer recoverPanic(c.logger, &err)
+	tracer := c.newTracer()
+	if tracer != nil {
+		ctx = httptrace.WithClientTrace(ctx, tracer.clientTrace())
+	}
+	ctx, deadline := c.newDeadline(ctx, r.op)
+	req, err := c.createReq(ctx, r)
+	if err != nil {
+		deadline.stop()
+		return nil, err
+	}
+	deadline.extend(requestBodySize(req))
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		err = deadline.wrap(err)
+		deadline.stop()
+		if tracer != nil {
+			c.requestDone(tracer, req.Method, r.path, 0, err)
+		}
+		return nil, err
+	}
+	c.recordServerVersion(res.Header)
+	if deadline != nil {
+		deadline.extend(res.ContentLength)
+		res.Body = &deadlineBody{ReadCloser: res.Body, deadline: deadline}
+	}
+	if tracer != nil {
+		res.Body = &tracedBody{
+			ReadCloser: res.Body,
+			done: func() {
+				c.requestDone(tracer, req.Method, r.path, res.StatusCode, nil)
+			},
+		}
+	}
+	return res, nil
+}
+
+// newTracer returns a tracer if request timings are needed, nil otherwise.
+func (c *Client) newTracer() *requestTracer {
+	if c.slowRequestThreshold <= 0 && c.hooks.OnRequest == nil {
+		return nil
+	}
+	return newRequestTracer()
+}
+
 type ClientOptions struct {
-	Host       string
-	ApiKey     string
-	HTTPCLient *http.Client
-	Timeout    *time.Duration
-	err        error
+	Host                      string
+	ApiKey                    string
+	HTTPCLient                *http.Client
+	Timeout                   *time.Duration
+	DeadlinePerMB             time.Duration
+	Logger                    Logger
+	SlowRequestThreshold      time.Duration
+	Hooks                     Hooks
+	Limits                    *Limits
+	RequireDeleteConfirmation bool
+	BucketCacheTTL            time.Duration
+	ListRetries               *int
+	Origin                    OriginFunc
+	OriginBackfill            bool
+	TenantPolicy              *TenantPolicy
+	OperationTimeouts         map[OperationName]time.Duration
+	DialOptions               *DialOptions
+	NoncePolicyTTL            time.Duration
+	StrictResponses           bool
+	err                       error
 }
 
 func NewClientOptions() *ClientOptions {
@@ -140,13 +303,120 @@ func (c *ClientOptions) SetApiKey(apiKey string) *ClientOptions {
 	return c
 }
 
-// SetTimeout specifies a timeout that is used for creating connections to the server.
+// SetTimeout specifies the time limit for requests to the server, including reading the response body.
 // If set to 0, no timeout will be used. The default is 30 seconds.
 func (c *ClientOptions) SetTimout(timeout time.Duration) *ClientOptions {
 	c.Timeout = &timeout
 	return c
 }
 
+// SetOperationTimeout overrides the timeout set with SetTimout for a single operation, e.g. to give
+// CompleteArchive more time than ListObjects. If set to 0, requests of the operation have no timeout.
+func (c *ClientOptions) SetOperationTimeout(op OperationName, timeout time.Duration) *ClientOptions {
+	if c.OperationTimeouts == nil {
+		c.OperationTimeouts = make(map[OperationName]time.Duration)
+	}
+	c.OperationTimeouts[op] = timeout
+	return c
+}
+
+// SetDeadlinePerMB extends the timeout of each request by the given duration for every MB of payload,
+// so that large uploads and downloads are not aborted by a timeout that suits small requests.
+// The payload size is taken from the request and response Content-Length.
+// If set to 0, the timeout is not extended. This is the default.
+func (c *ClientOptions) SetDeadlinePerMB(d time.Duration) *ClientOptions {
+	c.DeadlinePerMB = d
+	return c
+}
+
+// SetLogger sets the logger used by the client. The default is log.Default().
+func (c *ClientOptions) SetLogger(logger Logger) *ClientOptions {
+	c.Logger = logger
+	return c
+}
+
+// SetSlowRequestThreshold enables logging of requests that take longer than the given threshold.
+// The log message contains a timing breakdown of DNS lookup, connect, TLS handshake, time to first byte and body transfer.
+// If set to 0, slow requests are not logged. This is the default.
+func (c *ClientOptions) SetSlowRequestThreshold(threshold time.Duration) *ClientOptions {
+	c.SlowRequestThreshold = threshold
+	return c
+}
+
+// SetHooks sets the callbacks that are invoked by the client.
+func (c *ClientOptions) SetHooks(hooks Hooks) *ClientOptions {
+	c.Hooks = hooks
+	return c
+}
+
+// SetLimits sets the server limits used to validate commands. The default is DefaultLimits(), which must be
+// overridden for servers that are configured with different limits. Fields that are not set take their value
+// from DefaultLimits().
+func (c *ClientOptions) SetLimits(limits Limits) *ClientOptions {
+	c.Limits = &limits
+	return c
+}
+
+// SetRequireDeleteConfirmation requires DeleteBucketCommand.ConfirmName to be set to the bucket name.
+// This is a guardrail against automation deleting the wrong bucket.
+func (c *ClientOptions) SetRequireDeleteConfirmation(require bool) *ClientOptions {
+	c.RequireDeleteConfirmation = require
+	return c
+}
+
+// SetBucketCacheTTL enables caching of ListBuckets and BucketExists results for the given duration.
+// The cache is invalidated when buckets are created or deleted through the client.
+// If set to 0, results are not cached. This is the default.
+func (c *ClientOptions) SetBucketCacheTTL(ttl time.Duration) *ClientOptions {
+	c.BucketCacheTTL = ttl
+	return c
+}
+
+// SetListRetries sets how often a page of a multi-page listing is retried if the server is
+// temporarily unavailable, with exponential backoff between attempts. The default is 5.
+func (c *ClientOptions) SetListRetries(retries int) *ClientOptions {
+	c.ListRetries = &retries
+	return c
+}
+
+// SetOrigin sets a fallback that ReadObject uses for objects that cannot be found.
+// If backfill is true, objects read from the origin are stored in the bucket unless the key has been written in
+// the meantime. Backfilled objects are buffered in memory, objects larger than 64 MiB are not backfilled.
+func (c *ClientOptions) SetOrigin(origin OriginFunc, backfill bool) *ClientOptions {
+	c.Origin = origin
+	c.OriginBackfill = backfill
+	return c
+}
+
+// SetTenantPolicy restricts the buckets and keys the client may use.
+func (c *ClientOptions) SetTenantPolicy(policy TenantPolicy) *ClientOptions {
+	c.TenantPolicy = &policy
+	return c
+}
+
+// SetDialOptions configures how connections to the server are established, e.g. to use a custom resolver,
+// restrict the IP version or cache DNS lookups. The transport of the HTTP client is cloned and its dial
+// function is replaced.
+func (c *ClientOptions) SetDialOptions(opts DialOptions) *ClientOptions {
+	c.DialOptions = &opts
+	return c
+}
+
+// SetNoncePolicyEnforcement makes CreateNonce check the nonce policy of the bucket before a nonce is created.
+// Policies are cached for the given duration. If set to 0, policies are only enforced by the server. This is the default.
+func (c *ClientOptions) SetNoncePolicyEnforcement(ttl time.Duration) *ClientOptions {
+	c.NoncePolicyTTL = ttl
+	return c
+}
+
+// SetStrictResponses rejects responses that do not have a JSON content type with ErrUnexpectedResponse before
+// they are decoded, e.g. error pages of a proxy that are returned with a success status.
+// Responses that cannot be decoded fail with ErrUnexpectedResponse in any case.
+func (c *ClientOptions) SetStrictResponses(strict bool) *ClientOptions {
+	c.StrictResponses = strict
+	return c
+}
+
 // Validate validates the client options. This method will return the first error found.
 func (c *ClientOptions) Validate() error {
 	if c.err != nil {
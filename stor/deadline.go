@@ -0,0 +1,108 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// requestDeadline cancels a request once the client timeout has elapsed.
+// The deadline is extended proportionally to the transferred payload if a deadline per MB is configured.
+type requestDeadline struct {
+	mu       sync.Mutex
+	timer    *time.Timer
+	deadline time.Time
+	perMB    time.Duration
+	expired  bool
+	cancel   context.CancelFunc
+}
+
+// newDeadline derives a context that is canceled once the timeout of the operation has elapsed.
+// It returns nil if no timeout is configured.
+func (c *Client) newDeadline(ctx context.Context, op OperationName) (context.Context, *requestDeadline) {
+	timeout, ok := c.operationTimeouts[op]
+	if !ok {
+		timeout = c.timeout
+	}
+	if timeout <= 0 {
+		return ctx, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	d := &requestDeadline{
+		deadline: time.Now().Add(timeout),
+		perMB:    c.deadlinePerMB,
+		cancel:   cancel,
+	}
+	d.timer = time.AfterFunc(timeout, d.expire)
+	return ctx, d
+}
+
+func (d *requestDeadline) expire() {
+	d.mu.Lock()
+	d.expired = true
+	d.mu.Unlock()
+	d.cancel()
+}
+
+// extend extends the deadline for a payload of the given size.
+func (d *requestDeadline) extend(size int64) {
+	if d == nil || d.perMB <= 0 || size <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.expired || !d.timer.Stop() {
+		return
+	}
+	mb := (size + 1<<20 - 1) >> 20
+	d.deadline = d.deadline.Add(time.Duration(mb) * d.perMB)
+	d.timer.Reset(time.Until(d.deadline))
+}
+
+// stop releases the resources of the deadline.
+func (d *requestDeadline) stop() {
+	if d == nil {
+		return
+	}
+	d.timer.Stop()
+	d.cancel()
+}
+
+// wrap replaces err with a timeout error if the deadline has expired.
+func (d *requestDeadline) wrap(err error) error {
+	if d == nil || err == nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.expired {
+		return fmt.Errorf("request timed out: %w", context.DeadlineExceeded)
+	}
+	return err
+}
+
+// deadlineBody stops the deadline once the response body is closed.
+type deadlineBody struct {
+	io.ReadCloser
+	deadline *requestDeadline
+}
+
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != io.EOF {
+		err = b.deadline.wrap(err)
+	}
+	return n, err
+}
+
+func (b *deadlineBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.deadline.stop()
+	return err
+}
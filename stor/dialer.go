@@ -0,0 +1,189 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IPPreference selects the IP versions used to connect to the server.
+type IPPreference string
+
+const (
+	// IPAny uses IPv4 and IPv6 with happy-eyeballs dialing. This is the default.
+	IPAny IPPreference = ""
+	// IPv4Only connects only over IPv4.
+	IPv4Only IPPreference = "ipv4"
+	// IPv6Only connects only over IPv6.
+	IPv6Only IPPreference = "ipv6"
+)
+
+// DialOptions configure how the client connects to the server.
+type DialOptions struct {
+	// Resolver is used to look up host names. Defaults to net.DefaultResolver.
+	Resolver *net.Resolver
+	// IPPreference restricts the IP versions used to connect.
+	IPPreference IPPreference
+	// FallbackDelay is the time to wait for an IPv6 connection before falling back to IPv4.
+	// Defaults to 300ms. A negative value disables the fallback.
+	FallbackDelay time.Duration
+	// DNSCacheTTL caches resolved addresses for the given duration. Cached addresses are tried in order.
+	// If set to 0, every connection resolves the host name. This is the default.
+	DNSCacheTTL time.Duration
+	// Timeout is the maximum time to establish a connection. Defaults to 30s.
+	Timeout time.Duration
+}
+
+func (o DialOptions) network() string {
+	switch o.IPPreference {
+	case IPv4Only:
+		return "tcp4"
+	case IPv6Only:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+// dialer dials connections according to DialOptions.
+type dialer struct {
+	opts   DialOptions
+	dialer *net.Dialer
+	mu     sync.Mutex
+	cache  map[string]dnsCacheEntry
+}
+
+type dnsCacheEntry struct {
+	addrs     []net.IPAddr
+	expiresAt time.Time
+}
+
+func newDialer(opts DialOptions) *dialer {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &dialer{
+		opts: opts,
+		dialer: &net.Dialer{
+			Resolver:      opts.Resolver,
+			Timeout:       timeout,
+			KeepAlive:     30 * time.Second,
+			FallbackDelay: opts.FallbackDelay,
+		},
+		cache: make(map[string]dnsCacheEntry),
+	}
+}
+
+// httpClient creates an HTTP client that uses the dialer. The transport of base is cloned if it is an *http.Transport.
+func (d *dialer) httpClient(base *http.Client) *http.Client {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if base != nil {
+		if t, isTransport := base.Transport.(*http.Transport); isTransport {
+			transport, ok = t, true
+		}
+	}
+	var t *http.Transport
+	if ok {
+		t = transport.Clone()
+	} else {
+		t = &http.Transport{}
+	}
+	t.DialContext = d.dialContext
+
+	client := &http.Client{Transport: t}
+	if base != nil {
+		client.CheckRedirect = base.CheckRedirect
+		client.Jar = base.Jar
+		client.Timeout = base.Timeout
+	}
+	return client
+}
+
+func (d *dialer) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	network = d.network(network)
+	if d.opts.DNSCacheTTL <= 0 {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, addr := range addrs {
+		if !d.matches(addr.IP) {
+			continue
+		}
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	// the cached addresses may be outdated, resolve again on the next dial
+	d.mu.Lock()
+	delete(d.cache, host)
+	d.mu.Unlock()
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no suitable address found for %s", host)
+	}
+	return nil, lastErr
+}
+
+// network narrows the requested network to the configured IP preference.
+func (d *dialer) network(network string) string {
+	if network != "tcp" {
+		return network
+	}
+	return d.opts.network()
+}
+
+func (d *dialer) matches(ip net.IP) bool {
+	switch d.opts.IPPreference {
+	case IPv4Only:
+		return ip.To4() != nil
+	case IPv6Only:
+		return ip.To4() == nil
+	default:
+		return true
+	}
+}
+
+func (d *dialer) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	d.mu.Lock()
+	e, ok := d.cache[host]
+	d.mu.Unlock()
+	if ok && time.Now().Before(e.expiresAt) {
+		return e.addrs, nil
+	}
+	resolver := d.opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		if ok {
+			// serve stale addresses if DNS is temporarily unavailable
+			return e.addrs, nil
+		}
+		return nil, err
+	}
+	d.mu.Lock()
+	d.cache[host] = dnsCacheEntry{addrs: addrs, expiresAt: time.Now().Add(d.opts.DNSCacheTTL)}
+	d.mu.Unlock()
+	return addrs, nil
+}
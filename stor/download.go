@@ -0,0 +1,229 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+const (
+	defaultDownloadPartSize    = 8 << 20
+	defaultDownloadConcurrency = 4
+)
+
+// rangeDownload downloads an object with concurrent range requests into an io.WriterAt.
+type rangeDownload struct {
+	client      *Client
+	bucket      string
+	key         string
+	w           io.WriterAt
+	offset      int64
+	partSize    int64
+	concurrency int
+	// maxSize fails the download with ErrObjectTooLarge if the object is larger. 0 means no limit.
+	maxSize int64
+	// etag pins all range requests to a version of the object. If empty, it is taken from the first response.
+	etag string
+
+	mu       sync.Mutex
+	checksum *expectedChecksum
+	size     int64
+	parts    []bool
+}
+
+// run downloads the object starting at the offset. It returns the size of the object.
+func (d *rangeDownload) run(ctx context.Context) (int64, error) {
+	if d.partSize <= 0 {
+		d.partSize = defaultDownloadPartSize
+	}
+	if d.concurrency <= 0 {
+		d.concurrency = defaultDownloadConcurrency
+	}
+
+	res, err := d.get(ctx, d.offset, d.partSize)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		// the server ignored the range, the body contains the whole object
+		if err := d.checkSize(res.ContentLength); err != nil {
+			return 0, err
+		}
+		var body io.Reader = res.Body
+		if d.maxSize > 0 {
+			body = &maxSizeReader{ReadCloser: res.Body, remaining: d.maxSize}
+		}
+		n, err := io.Copy(&offsetWriter{w: d.w}, body)
+		if err != nil {
+			return 0, err
+		}
+		d.etag = res.Header.Get("ETag")
+		d.offset = 0
+		d.partSize = n
+		d.size = n
+		d.parts = []bool{true}
+		if !res.Uncompressed {
+			d.checksum = expectedChecksumFromHeader(res.Header)
+		}
+		return n, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		size, err := parseContentRangeSize(res.Header.Get("Content-Range"))
+		if err != nil {
+			return 0, err
+		}
+		if size > d.offset {
+			return 0, fmt.Errorf("unable to download range at %d of %d bytes", d.offset, size)
+		}
+		d.setSize(size)
+		return size, nil
+	case http.StatusPartialContent:
+	case http.StatusNotFound:
+		return 0, ErrObjectNotFound
+	case http.StatusPreconditionFailed:
+		return 0, fmt.Errorf("%w: object changed since the download started", ErrPreconditionFailed)
+	default:
+		return 0, fmt.Errorf("unable to download object: %v", res.StatusCode)
+	}
+
+	size, err := parseContentRangeSize(res.Header.Get("Content-Range"))
+	if err != nil {
+		return 0, err
+	}
+	if err := d.checkSize(size); err != nil {
+		return 0, err
+	}
+	// the remaining parts must belong to the same version of the object
+	if d.etag == "" {
+		d.etag = res.Header.Get("ETag")
+	}
+	d.checksum = expectedChecksumFromHeader(res.Header)
+	d.setSize(size)
+	if _, err := io.Copy(&offsetWriter{w: d.w, offset: d.offset}, res.Body); err != nil {
+		return size, err
+	}
+	d.partDone(0)
+
+	err = Batch{Concurrency: d.concurrency, StopOnError: true, Logger: d.client.logger}.Run(ctx, len(d.parts)-1, func(ctx context.Context, i int) error {
+		return d.downloadPart(ctx, i+1)
+	})
+	return size, err
+}
+
+func (d *rangeDownload) checkSize(size int64) error {
+	if d.maxSize > 0 && size > d.maxSize {
+		return fmt.Errorf("%w: %d bytes exceeds the maximum of %d", ErrObjectTooLarge, size, d.maxSize)
+	}
+	return nil
+}
+
+func (d *rangeDownload) setSize(size int64) {
+	d.size = size
+	remaining := size - d.offset
+	n := 1
+	if remaining > d.partSize {
+		n = int((remaining + d.partSize - 1) / d.partSize)
+	}
+	d.parts = make([]bool, n)
+}
+
+func (d *rangeDownload) downloadPart(ctx context.Context, part int) error {
+	start := d.offset + int64(part)*d.partSize
+	res, err := d.get(ctx, start, d.partSize)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusPreconditionFailed {
+		return fmt.Errorf("%w: object changed during download", ErrPreconditionFailed)
+	}
+	if res.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("unable to download range at %d: %v", start, res.StatusCode)
+	}
+	if _, err := io.Copy(&offsetWriter{w: d.w, offset: start}, res.Body); err != nil {
+		return err
+	}
+	d.partDone(part)
+	return nil
+}
+
+func (d *rangeDownload) get(ctx context.Context, start, length int64) (*http.Response, error) {
+	header := http.Header{}
+	header.Set("Range", ByteRange{Start: start, End: start + length - 1}.header())
+	if d.etag != "" {
+		header.Set("If-Match", d.etag)
+	}
+	return d.client.streamReq(ctx, R{
+		op:     OperationReadObject,
+		path:   objectPath(d.bucket, d.key),
+		header: header,
+	})
+}
+
+func (d *rangeDownload) partDone(part int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.parts[part] = true
+}
+
+// resumeOffset returns the offset up to which the object has been downloaded without gaps.
+func (d *rangeDownload) resumeOffset() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	offset := d.offset
+	for _, done := range d.parts {
+		if !done {
+			break
+		}
+		offset += d.partSize
+	}
+	if d.size > 0 && offset > d.size {
+		offset = d.size
+	}
+	return offset
+}
+
+// parseContentRangeSize returns the complete length of a Content-Range header.
+func parseContentRangeSize(header string) (int64, error) {
+	cr, err := parseContentRange(header)
+	if err != nil {
+		return 0, err
+	}
+	if cr.Size < 0 {
+		return 0, fmt.Errorf("Content-Range without size: %q", header)
+	}
+	return cr.Size, nil
+}
+
+// offsetWriter writes sequentially to an io.WriterAt, starting at offset.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.w.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+// verify verifies the downloaded object against the checksum reported by the server by reading it back from r.
+// Objects without a verifiable checksum are not verified.
+func (d *rangeDownload) verify(r io.ReaderAt) error {
+	if d.checksum == nil {
+		return nil
+	}
+	h := d.checksum.newHash()
+	if _, err := io.Copy(h, io.NewSectionReader(r, 0, d.size)); err != nil {
+		return fmt.Errorf("unable to verify download: %w", err)
+	}
+	return d.checksum.verify(h.Sum(nil))
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+type DownloaderOptions struct {
+	// PartSize is the size of the ranges that are downloaded concurrently. Defaults to 8 MB.
+	PartSize int64
+	// Concurrency is the number of concurrent range requests. Defaults to 4.
+	Concurrency int
+}
+
+// Downloader downloads objects with concurrent range requests. All ranges are read from the same version
+// of the object; if the object changes during the download, ErrPreconditionFailed is returned.
+type Downloader struct {
+	client      *Client
+	partSize    int64
+	concurrency int
+}
+
+type DownloadInput struct {
+	Bucket string
+	Key    string
+	// Offset resumes a previous download at the given offset, usually DownloadOutput.ResumeOffset.
+	// IfMatch must be set as well, so that the resumed ranges belong to the same version of the object.
+	Offset int64
+	// IfMatch downloads the object only if its ETag matches, usually DownloadOutput.ETag of the download that
+	// is resumed. Otherwise, ErrPreconditionFailed is returned.
+	IfMatch string
+	// MaxSize refuses to download objects larger than the given number of bytes with ErrObjectTooLarge.
+	MaxSize int64
+	// VerifyChecksum verifies the download against the checksum or MD5 digest reported by the server
+	// by reading the written data back. It requires the io.WriterAt to implement io.ReaderAt, like *os.File.
+	// If the data does not match, ErrChecksumMismatch is returned.
+	VerifyChecksum bool
+}
+
+type DownloadOutput struct {
+	// Size is the size of the object.
+	Size int64
+	// ETag is the ETag of the downloaded version of the object. It is empty if no response was received.
+	ETag string
+	// ResumeOffset is the offset up to which the object has been written without gaps.
+	// If the download fails, it can be resumed from this offset.
+	ResumeOffset int64
+}
+
+// NewDownloader creates a new Downloader.
+func (c *Client) NewDownloader(opts DownloaderOptions) *Downloader {
+	return &Downloader{
+		client:      c,
+		partSize:    opts.PartSize,
+		concurrency: opts.Concurrency,
+	}
+}
+
+// Download downloads an object into w, e.g. an *os.File.
+// The output is returned even if the download fails, so that the download can be resumed.
+func (d *Downloader) Download(ctx context.Context, input DownloadInput, w io.WriterAt) (*DownloadOutput, error) {
+	readBack, canVerify := w.(io.ReaderAt)
+	if input.VerifyChecksum && !canVerify {
+		return nil, fmt.Errorf("%w: VerifyChecksum requires an io.ReaderAt", ErrInvalidArgument)
+	}
+	if input.Offset > 0 && input.IfMatch == "" {
+		return nil, fmt.Errorf("%w: resuming a download at an offset requires IfMatch", ErrInvalidArgument)
+	}
+	r := &rangeDownload{
+		client:      d.client,
+		bucket:      input.Bucket,
+		key:         input.Key,
+		w:           w,
+		offset:      input.Offset,
+		partSize:    d.partSize,
+		concurrency: d.concurrency,
+		maxSize:     input.MaxSize,
+		etag:        input.IfMatch,
+	}
+	size, err := r.run(ctx)
+	if err == nil && input.VerifyChecksum {
+		err = r.verify(readBack)
+	}
+	return &DownloadOutput{
+		Size:         size,
+		ETag:         r.etag,
+		ResumeOffset: r.resumeOffset(),
+	}, err
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// defaultFileMode is the mode of downloaded files that do not replace an existing file. It is the mode that
+// os.Create and the usual umask of 022 result in.
+const defaultFileMode os.FileMode = 0644
+
+type DownloadFileResult struct {
+	ContentType string
+	ETag        string
+	Size        int64
+}
+
+// DownloadFile downloads an object to the file at path. The content is written to a temporary file in the same
+// directory, which is renamed to path once the download is complete. If the download fails, path is left untouched
+// and the temporary file is removed.
+//
+// If path exists, the downloaded file keeps its permissions. Otherwise, it is created with defaultFileMode.
+func (c *Client) DownloadFile(ctx context.Context, bucket, key, path string) (*DownloadFileResult, error) {
+	res, err := c.ReadObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create file: %w", err)
+	}
+	tmp := f.Name()
+	size, err := io.Copy(f, res)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		// temporary files are only accessible by the owner
+		mode := defaultFileMode
+		if info, statErr := os.Stat(path); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+		err = f.Chmod(mode)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("unable to download object: %w", err)
+	}
+
+	return &DownloadFileResult{
+		ContentType: res.ContentType,
+		ETag:        res.ETag,
+		Size:        size,
+	}, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadFile(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello"))
+	})
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "hello.txt")
+	if _, err := client.DownloadFile(context.Background(), "bucket", "hello.txt", path); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "hello" {
+		t.Errorf("file = %q, %v", data, err)
+	}
+
+	_, err := client.DownloadFile(context.Background(), "bucket", "hello.txt", filepath.Join(dir, "missing", "hello.txt"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("DownloadFile into a missing directory = %v, want %v", err, fs.ErrNotExist)
+	}
+}
@@ -3,5 +3,22 @@ package stor
 import "fmt"
 
 var (
-	ErrObjectNotFound = fmt.Errorf("object not found")
+	ErrObjectNotFound  = fmt.Errorf("object not found")
+	ErrInvalidArgument = fmt.Errorf("invalid argument")
+	// ErrUploadNotFound is returned if a multipart upload does not exist, e.g. because it was completed or aborted.
+	ErrUploadNotFound = fmt.Errorf("upload not found")
+	// ErrBucketNotFound is returned if a bucket does not exist.
+	ErrBucketNotFound = fmt.Errorf("bucket not found")
+	// ErrBucketAlreadyExists is returned if a bucket cannot be created because it already exists.
+	ErrBucketAlreadyExists = fmt.Errorf("bucket already exists")
+	// ErrPreconditionFailed is returned if a condition like IfNoneMatch was not met.
+	ErrPreconditionFailed = fmt.Errorf("precondition failed")
+	// ErrNotModified is returned by conditional reads if the object has not been modified.
+	ErrNotModified = fmt.Errorf("not modified")
+	// ErrObjectTooLarge is returned if an object exceeds the maximum download size.
+	ErrObjectTooLarge = fmt.Errorf("object too large")
+	// ErrServiceUnavailable is returned if the server is temporarily unable to handle a request.
+	ErrServiceUnavailable = fmt.Errorf("service unavailable")
+	// ErrInternalClient is returned if the client failed unexpectedly, e.g. due to a panic in a worker.
+	ErrInternalClient = fmt.Errorf("internal client error")
 )
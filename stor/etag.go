@@ -0,0 +1,57 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MultipartETag computes the ETag of a multipart object from the MD5 checksums of its parts, in part order.
+// The ETag is the hex encoded MD5 of the concatenated part checksums, followed by "-" and the number of parts.
+func MultipartETag(partMD5s [][]byte) string {
+	h := md5.New()
+	for _, sum := range partMD5s {
+		h.Write(sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)) + "-" + strconv.Itoa(len(partMD5s))
+}
+
+// ComputeMultipartETag reads r in parts of partSize bytes and computes the ETag
+// the object would have when uploaded as a multipart upload with the same part size.
+func ComputeMultipartETag(r io.Reader, partSize int64) (string, error) {
+	if partSize <= 0 {
+		return "", fmt.Errorf("%w: part size must be positive, got %d", ErrInvalidArgument, partSize)
+	}
+	sums := make([][]byte, 0)
+	for {
+		h := md5.New()
+		n, err := io.CopyN(h, r, partSize)
+		if n > 0 {
+			sums = append(sums, h.Sum(nil))
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return MultipartETag(sums), nil
+}
+
+// ETagsEqual compares two ETags, ignoring surrounding quotes and weak validator prefixes.
+func ETagsEqual(a, b string) bool {
+	return normalizeETag(a) == normalizeETag(b)
+}
+
+func normalizeETag(etag string) string {
+	return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+}
@@ -0,0 +1,141 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	exportObjectsDir      = "objects/"
+	exportManifestName    = "manifest.json"
+	exportContentTypeName = "STOR.contentType"
+)
+
+type exportManifest struct {
+	Bucket     string    `json:"bucket"`
+	ExportedAt time.Time `json:"exportedAt"`
+	Objects    []*Object `json:"objects"`
+}
+
+// ExportBucket writes all objects of a bucket as a tar stream to w.
+// Objects are stored below "objects/", followed by a "manifest.json" describing the exported objects.
+func (c *Client) ExportBucket(ctx context.Context, bucket string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	manifest := exportManifest{
+		Bucket:     bucket,
+		ExportedAt: time.Now().UTC(),
+		Objects:    make([]*Object, 0),
+	}
+
+	err := c.walkObjects(ctx, ListObjectsCommand{Bucket: bucket}, func(page *ListObjectsResult) error {
+		for _, o := range page.Objects {
+			if err := c.exportObject(ctx, tw, bucket, o); err != nil {
+				return err
+			}
+			manifest.Objects = append(manifest.Objects, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     exportManifestName,
+		Size:     int64(len(data)),
+		Mode:     0644,
+		ModTime:  manifest.ExportedAt,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func (c *Client) exportObject(ctx context.Context, tw *tar.Writer, bucket string, o *Object) error {
+	res, err := c.ReadObjectWithOptions(ctx, ReadObjectCommand{Bucket: bucket, Key: o.Key})
+	if err != nil {
+		return fmt.Errorf("unable to read object %s: %w", o.Key, err)
+	}
+	defer res.Close()
+
+	size := res.ContentLength
+	if size < 0 {
+		size = o.Size
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag:   tar.TypeReg,
+		Name:       exportObjectsDir + o.Key,
+		Size:       size,
+		Mode:       0644,
+		ModTime:    o.CreatedAt,
+		Format:     tar.FormatPAX,
+		PAXRecords: map[string]string{exportContentTypeName: res.ContentType},
+	}); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, res); err != nil {
+		return fmt.Errorf("unable to export object %s: %w", o.Key, err)
+	}
+	return nil
+}
+
+// ImportBucket restores the objects of a tar stream created by ExportBucket into the given bucket.
+// Existing objects with the same keys are overwritten.
+func (c *Client) ImportBucket(ctx context.Context, bucket string, r io.Reader) error {
+	tr := tar.NewReader(&contextReader{ctx: ctx, r: r})
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !strings.HasPrefix(hdr.Name, exportObjectsDir) {
+			continue
+		}
+		key := strings.TrimPrefix(hdr.Name, exportObjectsDir)
+		if _, err := c.CreateObject(ctx, CreateObjectCommand{
+			Bucket:        bucket,
+			Key:           key,
+			ContentType:   hdr.PAXRecords[exportContentTypeName],
+			Data:          tr,
+			ContentLength: hdr.Size,
+		}); err != nil {
+			return fmt.Errorf("unable to import object %s: %w", key, err)
+		}
+	}
+}
+
+// ExportListing writes every object of a bucket as a JSON line to w.
+// Pages are fetched one at a time, so memory usage does not grow with the size of the bucket.
+func (c *Client) ExportListing(ctx context.Context, bucket string, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return c.walkObjects(ctx, ListObjectsCommand{Bucket: bucket}, func(page *ListObjectsResult) error {
+		for _, o := range page.Objects {
+			if err := enc.Encode(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
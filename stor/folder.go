@@ -0,0 +1,104 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CreateFolder creates a zero-byte placeholder object for the given prefix so that empty folders
+// show up in listings. A trailing "/" is added to the prefix if it is missing.
+func (c *Client) CreateFolder(ctx context.Context, bucket, prefix string) error {
+	_, err := c.CreateObject(ctx, CreateObjectCommand{
+		Bucket: bucket,
+		Key:    folderKey(prefix),
+	})
+	return err
+}
+
+// DeleteFolder deletes the placeholder object of the given prefix.
+// Objects below the prefix are not deleted.
+func (c *Client) DeleteFolder(ctx context.Context, bucket, prefix string) error {
+	return c.deleteObject(ctx, bucket, folderKey(prefix))
+}
+
+func folderKey(prefix string) string {
+	if strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
+// deleteObject deletes a single object.
+func (c *Client) deleteObject(ctx context.Context, bucket, key string) error {
+	result, err := c.DeleteObjects(ctx, DeleteObjectsCommand{
+		Bucket:  bucket,
+		Objects: []ObjectReference{{Key: key}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range result.Results {
+		if r.Error != nil {
+			return fmt.Errorf("unable to delete object %s: %s", r.Key, r.Error.Message)
+		}
+	}
+	return nil
+}
+
+type DeleteByPrefixResult struct {
+	// Deleted is the number of deleted objects.
+	Deleted int
+	// Failed contains the results of the objects that could not be deleted.
+	Failed []DeleteResult
+}
+
+// DeleteByPrefix deletes all objects below prefix, batching the keys of every listed page into DeleteObjects
+// requests. Objects that the server refuses to delete are reported in the result.
+// An empty prefix is rejected, use DeleteObjects to empty a bucket on purpose.
+//
+// If ctx is canceled, the objects deleted so far are reported together with the error.
+func (c *Client) DeleteByPrefix(ctx context.Context, bucket, prefix string) (*DeleteByPrefixResult, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: prefix must not be empty", ErrInvalidArgument)
+	}
+	result := &DeleteByPrefixResult{}
+	err := c.walkObjects(ctx, ListObjectsCommand{
+		Bucket: bucket,
+		Prefix: prefix,
+	}, func(page *ListObjectsResult) error {
+		for start := 0; start < len(page.Objects); start += c.limits.MaxBatchDelete {
+			end := start + c.limits.MaxBatchDelete
+			if end > len(page.Objects) {
+				end = len(page.Objects)
+			}
+			refs := make([]ObjectReference, 0, end-start)
+			for _, o := range page.Objects[start:end] {
+				refs = append(refs, ObjectReference{Key: o.Key})
+			}
+			res, err := c.DeleteObjects(ctx, DeleteObjectsCommand{
+				Bucket:  bucket,
+				Objects: refs,
+			})
+			if err != nil {
+				return err
+			}
+			for _, r := range res.Results {
+				if r.Deleted {
+					result.Deleted++
+				} else {
+					result.Failed = append(result.Failed, r)
+				}
+			}
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// GCPolicy defines which resources are garbage collected.
+type GCPolicy struct {
+	// TempPrefix is the key prefix of temporary objects. If empty, no temporary objects are removed.
+	TempPrefix string
+	// TempMaxAge is the age after which temporary objects are removed.
+	TempMaxAge time.Duration
+	// UploadMaxAge is the age after which multipart uploads are aborted. If 0, no uploads are aborted.
+	UploadMaxAge time.Duration
+	// Archives are the archives that are checked. The server does not allow listing archives, so callers
+	// have to keep track of the archives they created.
+	Archives []GCArchive
+	// ArchiveMaxAge is the age after which failed archives are deleted. If 0, no archives are deleted.
+	ArchiveMaxAge time.Duration
+}
+
+// GCArchive identifies an archive for garbage collection.
+type GCArchive struct {
+	Key       string
+	ArchiveId string
+	// CreatedAt is the time the archive was created, usually recorded when CreateArchive returned.
+	CreatedAt time.Time
+}
+
+// GCReport summarizes a garbage collection run.
+type GCReport struct {
+	DeletedObjects  int
+	ReclaimedBytes  int64
+	AbortedUploads  int
+	DeletedArchives int
+}
+
+// GC removes stale resources of a bucket according to the policy and reports the reclaimed storage.
+// Failed archives are deleted with AbortArchive; archives in any other state are kept.
+func (c *Client) GC(ctx context.Context, bucket string, policy GCPolicy) (*GCReport, error) {
+	report := &GCReport{}
+	if policy.UploadMaxAge > 0 {
+		if err := c.abortStaleUploads(ctx, bucket, policy.UploadMaxAge, report); err != nil {
+			return report, err
+		}
+	}
+	if policy.ArchiveMaxAge > 0 {
+		if err := c.deleteFailedArchives(ctx, bucket, policy.Archives, policy.ArchiveMaxAge, report); err != nil {
+			return report, err
+		}
+	}
+	if policy.TempPrefix == "" {
+		return report, nil
+	}
+	cutoff := time.Now().Add(-policy.TempMaxAge)
+	err := c.walkObjects(ctx, ListObjectsCommand{
+		Bucket: bucket,
+		Prefix: policy.TempPrefix,
+	}, func(page *ListObjectsResult) error {
+		stale := make([]*Object, 0)
+		for _, o := range page.Objects {
+			if o.CreatedAt.Before(cutoff) {
+				stale = append(stale, o)
+			}
+		}
+		deleted, err := c.deleteObjects(ctx, bucket, stale)
+		for _, o := range deleted {
+			report.DeletedObjects++
+			report.ReclaimedBytes += o.Size
+		}
+		return err
+	})
+	return report, err
+}
+
+// abortStaleUploads aborts multipart uploads that were initiated more than maxAge ago.
+func (c *Client) abortStaleUploads(ctx context.Context, bucket string, maxAge time.Duration, report *GCReport) error {
+	cutoff := time.Now().Add(-maxAge)
+	stale := make([]*MultipartUpload, 0)
+	err := c.walkUploads(ctx, ListMultipartUploadsCommand{
+		Bucket: bucket,
+	}, func(page *ListMultipartUploadsResult) error {
+		for _, u := range page.Uploads {
+			if u.InitiatedAt.Before(cutoff) {
+				stale = append(stale, u)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, u := range stale {
+		err := c.AbortMultipartUpload(ctx, AbortMultipartUploadCommand{
+			Bucket:   bucket,
+			Key:      u.Key,
+			UploadId: u.UploadId,
+		})
+		if err != nil {
+			return err
+		}
+		report.AbortedUploads++
+	}
+	return nil
+}
+
+// deleteFailedArchives deletes the archives that were created more than maxAge ago and have failed.
+func (c *Client) deleteFailedArchives(ctx context.Context, bucket string, archives []GCArchive, maxAge time.Duration, report *GCReport) error {
+	cutoff := time.Now().Add(-maxAge)
+	for _, a := range archives {
+		if !a.CreatedAt.Before(cutoff) {
+			continue
+		}
+		archive, err := c.GetArchive(ctx, GetArchiveCommand{Bucket: bucket, Key: a.Key, ArchiveId: a.ArchiveId})
+		if errors.Is(err, ErrArchiveNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if archive.State != ArchiveStateFailed {
+			continue
+		}
+		if err := c.AbortArchive(ctx, AbortArchiveCommand{Bucket: bucket, Key: a.Key, ArchiveId: a.ArchiveId}); err != nil {
+			return err
+		}
+		report.DeletedArchives++
+	}
+	return nil
+}
+
+// deleteObjects deletes the objects in batches of at most Limits.MaxBatchDelete and returns the deleted objects.
+func (c *Client) deleteObjects(ctx context.Context, bucket string, objects []*Object) ([]*Object, error) {
+	deleted := make([]*Object, 0, len(objects))
+	for start := 0; start < len(objects); start += c.limits.MaxBatchDelete {
+		end := start + c.limits.MaxBatchDelete
+		if end > len(objects) {
+			end = len(objects)
+		}
+		batch := objects[start:end]
+		byKey := make(map[string]*Object, len(batch))
+		refs := make([]ObjectReference, len(batch))
+		for i, o := range batch {
+			byKey[o.Key] = o
+			refs[i] = ObjectReference{Key: o.Key}
+		}
+		result, err := c.DeleteObjects(ctx, DeleteObjectsCommand{
+			Bucket:  bucket,
+			Objects: refs,
+		})
+		if err != nil {
+			return deleted, err
+		}
+		for _, r := range result.Results {
+			if r.Deleted {
+				deleted = append(deleted, byKey[r.Key])
+			}
+		}
+	}
+	return deleted, nil
+}
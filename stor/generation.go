@@ -0,0 +1,36 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+const generationHeader = "Stor-Generation"
+
+// setGenerationHeaders sets the generation conditions of a write. Conditions that are 0 are not set.
+func (c *Client) setGenerationHeaders(ctx context.Context, header http.Header, match, notMatch int64) error {
+	if match == 0 && notMatch == 0 {
+		return nil
+	}
+	if err := c.requireFeature(ctx, FeatureGenerations); err != nil {
+		return err
+	}
+	if match != 0 {
+		header.Set("Stor-If-Generation-Match", strconv.FormatInt(match, 10))
+	}
+	if notMatch != 0 {
+		header.Set("Stor-If-Generation-Not-Match", strconv.FormatInt(notMatch, 10))
+	}
+	return nil
+}
+
+// generationFromHeader returns the generation reported by the server, 0 if there is none.
+func generationFromHeader(header http.Header) int64 {
+	generation, _ := strconv.ParseInt(header.Get(generationHeader), 10, 64)
+	return generation
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// BucketDefaults are applied to every object created through a BucketHandle.
+// Values that are set on a command take precedence over the defaults.
+type BucketDefaults struct {
+	// ContentTypeByExt maps file extensions of keys, including the dot (e.g. ".json"), to content types.
+	// Extensions are matched case-insensitively.
+	ContentTypeByExt map[string]string
+	// CacheControl is used for objects without an explicit CacheControl.
+	CacheControl string
+	// Metadata is added to the metadata of every object. Metadata of the command takes precedence.
+	Metadata map[string]string
+}
+
+// BucketHandle gives access to a single bucket and applies the bucket's defaults to created objects.
+type BucketHandle struct {
+	client   *Client
+	name     string
+	defaults BucketDefaults
+}
+
+// NewBucketHandle creates a handle for the given bucket.
+func (c *Client) NewBucketHandle(name string, defaults BucketDefaults) *BucketHandle {
+	byExt := make(map[string]string, len(defaults.ContentTypeByExt))
+	for ext, contentType := range defaults.ContentTypeByExt {
+		byExt[strings.ToLower(ext)] = contentType
+	}
+	defaults.ContentTypeByExt = byExt
+	return &BucketHandle{
+		client:   c,
+		name:     name,
+		defaults: defaults,
+	}
+}
+
+// Name returns the name of the bucket.
+func (b *BucketHandle) Name() string {
+	return b.name
+}
+
+// CreateObject creates an object in the bucket of the handle, applying the bucket defaults.
+// The Bucket of the command is ignored.
+func (b *BucketHandle) CreateObject(ctx context.Context, cmd CreateObjectCommand) (*CreateObjectResult, error) {
+	cmd.Bucket = b.name
+	if cmd.ContentType == "" {
+		cmd.ContentType = b.defaults.ContentTypeByExt[strings.ToLower(path.Ext(cmd.Key))]
+	}
+	if cmd.CacheControl == "" {
+		cmd.CacheControl = b.defaults.CacheControl
+	}
+	if len(b.defaults.Metadata) > 0 {
+		metadata := make(map[string]string, len(b.defaults.Metadata)+len(cmd.Metadata))
+		for k, v := range b.defaults.Metadata {
+			metadata[strings.ToLower(k)] = v
+		}
+		for k, v := range cmd.Metadata {
+			metadata[strings.ToLower(k)] = v
+		}
+		cmd.Metadata = metadata
+	}
+	return b.client.CreateObject(ctx, cmd)
+}
@@ -0,0 +1,27 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+// Hooks are callbacks that are invoked by the client.
+// All callbacks are optional and must be safe for concurrent use.
+type Hooks struct {
+	// OnRequest is called after a request to the server has completed.
+	// For streamed responses like ReadObject, it is called when the response body is closed.
+	OnRequest func(RequestInfo)
+	// OnRetry is called with the retry decision for every failed attempt of a retrying operation,
+	// including the final attempt after which the client gives up.
+	OnRetry func(RetryInfo)
+}
+
+// RequestInfo describes a completed request.
+type RequestInfo struct {
+	Method string
+	Path   string
+	// StatusCode is the HTTP status code of the response. It is 0 if no response was received.
+	StatusCode int
+	// Err is the transport error of the request, if any.
+	Err    error
+	Timing RequestTiming
+}
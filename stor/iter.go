@@ -0,0 +1,60 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package stor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+)
+
+// Objects returns an iterator over all objects of the listing, fetching pages as needed.
+// If a page cannot be fetched, the iterator yields the error and stops.
+//
+//	for obj, err := range client.Objects(ctx, cmd) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (c *Client) Objects(ctx context.Context, cmd ListObjectsCommand) iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		p := NewListObjectsPaginator(c, cmd)
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, o := range page.Objects {
+				if !yield(o, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Records returns an iterator over the records of a query result. Reading stops at the first error.
+func (r *QueryObjectResult) Records() iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		for {
+			record, err := r.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
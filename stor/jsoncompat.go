@@ -0,0 +1,36 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// unmarshalStringFields decodes a JSON object into the given string fields, matching field names regardless of
+// case, underscores and hyphens, so that "uploadId", "UploadID" and "upload_id" are equivalent.
+// Older servers used different casings for some fields.
+func unmarshalStringFields(data []byte, fields map[string]*string) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for name, value := range raw {
+		field, ok := fields[normalizeFieldName(name)]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeFieldName(name string) string {
+	name = strings.ReplaceAll(name, "_", "")
+	name = strings.ReplaceAll(name, "-", "")
+	return strings.ToLower(name)
+}
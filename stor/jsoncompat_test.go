@@ -0,0 +1,161 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestCreateMultipartUploadResultLegacyCasings(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"camel case", `{"bucket":"b","key":"k","uploadId":"u"}`},
+		{"upper case id", `{"bucket":"b","key":"k","UploadID":"u"}`},
+		{"pascal case", `{"Bucket":"b","Key":"k","UploadId":"u"}`},
+		{"snake case", `{"bucket":"b","key":"k","upload_id":"u"}`},
+		{"kebab case", `{"bucket":"b","key":"k","upload-id":"u"}`},
+	}
+	want := CreateMultipartUploadResult{Bucket: "b", Key: "k", UploadId: "u"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got CreateMultipartUploadResult
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCreateArchiveResultLegacyCasings(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"camel case", `{"bucket":"b","key":"k","archiveId":"a"}`},
+		{"upper case id", `{"bucket":"b","key":"k","ArchiveID":"a"}`},
+		{"snake case", `{"bucket":"b","key":"k","archive_id":"a"}`},
+		{"unknown fields", `{"bucket":"b","key":"k","archiveId":"a","state":"pending","size":3}`},
+	}
+	want := CreateArchiveResult{Bucket: "b", Key: "k", ArchiveId: "a"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got CreateArchiveResult
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalStringFieldsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"not an object", `["uploadId"]`},
+		{"invalid json", `{"uploadId":`},
+		{"field not a string", `{"uploadId":42}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uploadId string
+			err := unmarshalStringFields([]byte(tt.json), map[string]*string{"uploadid": &uploadId})
+			if err == nil {
+				t.Errorf("expected an error, got uploadId %q", uploadId)
+			}
+		})
+	}
+}
+
+// legacyCasings returns the spellings of a camel case JSON field name that older servers used.
+func legacyCasings(name string) map[string]string {
+	var snake, kebab strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			snake.WriteByte('_')
+			kebab.WriteByte('-')
+		}
+		snake.WriteRune(unicode.ToLower(r))
+		kebab.WriteRune(unicode.ToLower(r))
+	}
+	pascal := strings.ToUpper(name[:1]) + name[1:]
+	upperId := strings.TrimSuffix(pascal, "Id")
+	if upperId != pascal {
+		upperId += "ID"
+	}
+	return map[string]string{
+		"camel":    name,
+		"pascal":   pascal,
+		"upper id": upperId,
+		"snake":    snake.String(),
+		"kebab":    kebab.String(),
+	}
+}
+
+// TestLegacyCasingsRoundTrip decodes every string field of the structs that accept legacy casings from each
+// casing and checks that encoding the result yields the canonical field names with the same values.
+func TestLegacyCasingsRoundTrip(t *testing.T) {
+	structs := []struct {
+		name string
+		new  func() interface{}
+	}{
+		{"CreateArchiveResult", func() interface{} { return &CreateArchiveResult{} }},
+		{"CreateMultipartUploadResult", func() interface{} { return &CreateMultipartUploadResult{} }},
+	}
+	for _, s := range structs {
+		typ := reflect.TypeOf(s.new()).Elem()
+		canonical := map[string]string{}
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.Type.Kind() != reflect.String {
+				t.Fatalf("%s.%s is not a string field", s.name, f.Name)
+			}
+			tag := strings.Split(f.Tag.Get("json"), ",")[0]
+			if tag == "" {
+				t.Fatalf("%s.%s has no json tag", s.name, f.Name)
+			}
+			canonical[tag] = "value of " + f.Name
+		}
+		for _, casing := range []string{"camel", "pascal", "upper id", "snake", "kebab"} {
+			t.Run(s.name+"/"+casing, func(t *testing.T) {
+				legacy := map[string]string{}
+				for name, value := range canonical {
+					legacy[legacyCasings(name)[casing]] = value
+				}
+				data, err := json.Marshal(legacy)
+				if err != nil {
+					t.Fatal(err)
+				}
+				v := s.new()
+				if err := json.Unmarshal(data, v); err != nil {
+					t.Fatalf("Unmarshal(%s): %v", data, err)
+				}
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got map[string]string
+				if err := json.Unmarshal(encoded, &got); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, canonical) {
+					t.Errorf("round trip of %s = %s, want %v", data, encoded, canonical)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+type PutObjectLegalHoldCommand struct {
+	Bucket string
+	Key    string
+	// VersionId places the hold on a specific version of the object in a versioned bucket.
+	VersionId string
+	// Hold locks the object against deletion and overwrites if true and releases the hold otherwise.
+	Hold bool
+}
+
+type GetObjectLegalHoldCommand struct {
+	Bucket string
+	Key    string
+	// VersionId returns the hold of a specific version of the object in a versioned bucket.
+	VersionId string
+}
+
+type legalHold struct {
+	Status string `json:"status"`
+}
+
+const (
+	legalHoldOn  = "ON"
+	legalHoldOff = "OFF"
+)
+
+// PutObjectLegalHold places or releases a legal hold on an object. While the hold is in place, the server refuses
+// to delete or overwrite the object. If the object cannot be found, the method returns ErrObjectNotFound.
+func (c *Client) PutObjectLegalHold(ctx context.Context, cmd PutObjectLegalHoldCommand) error {
+	query, err := c.legalHoldQuery(ctx, cmd.VersionId)
+	if err != nil {
+		return err
+	}
+	status := legalHoldOff
+	if cmd.Hold {
+		status = legalHoldOn
+	}
+	body, err := json.Marshal(legalHold{Status: status})
+	if err != nil {
+		return err
+	}
+	res, _, err := c.doReq(ctx, R{
+		op:          OperationPutObjectLegalHold,
+		method:      "PUT",
+		path:        objectPath(cmd.Bucket, cmd.Key),
+		query:       query,
+		contentType: "application/json",
+		body:        bytes.NewReader(body),
+	})
+	if err != nil {
+		return err
+	}
+	if res.StatusCode == 404 {
+		return ErrObjectNotFound
+	}
+	if res.StatusCode != 204 {
+		//TODO: map error
+		return fmt.Errorf("unable to put legal hold: %v", res.StatusCode)
+	}
+	return nil
+}
+
+// GetObjectLegalHold reports whether a legal hold is placed on an object.
+// If the object cannot be found, the method returns ErrObjectNotFound.
+func (c *Client) GetObjectLegalHold(ctx context.Context, cmd GetObjectLegalHoldCommand) (bool, error) {
+	query, err := c.legalHoldQuery(ctx, cmd.VersionId)
+	if err != nil {
+		return false, err
+	}
+	res, body, err := c.doReq(ctx, R{
+		op:    OperationGetObjectLegalHold,
+		path:  objectPath(cmd.Bucket, cmd.Key),
+		query: query,
+	})
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode == 404 {
+		return false, ErrObjectNotFound
+	}
+	if res.StatusCode != 200 {
+		//TODO: map error
+		return false, fmt.Errorf("unable to get legal hold: %v", res.StatusCode)
+	}
+	var hold legalHold
+	if err := c.unmarshalResponse(res, body, &hold); err != nil {
+		return false, err
+	}
+	return hold.Status == legalHoldOn, nil
+}
+
+func (c *Client) legalHoldQuery(ctx context.Context, versionId string) (url.Values, error) {
+	if err := c.requireFeature(ctx, FeatureLegalHold); err != nil {
+		return nil, err
+	}
+	query, err := c.versionQuery(ctx, versionId)
+	if err != nil {
+		return nil, err
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("legal-hold", "")
+	return query, nil
+}
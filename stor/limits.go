@@ -0,0 +1,106 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import "fmt"
+
+var (
+	ErrKeyTooLong        = fmt.Errorf("key too long")
+	ErrPartTooLarge      = fmt.Errorf("part too large")
+	ErrInvalidPartNumber = fmt.Errorf("invalid part number")
+	ErrTooManyParts      = fmt.Errorf("too many parts")
+	ErrTooManyObjects    = fmt.Errorf("too many objects")
+)
+
+// Limits are the limits of the STOR server. They are used to validate commands before they are sent.
+// The server does not report its limits, so the client cannot discover them. Clients of servers that are
+// configured differently must set the actual limits with ClientOptions.SetLimits.
+type Limits struct {
+	// MaxKeyLength is the maximum length of an object key in bytes.
+	MaxKeyLength int
+	// MaxPartSize is the maximum size of a part in a multipart upload.
+	MaxPartSize int64
+	// MinPartSize is the minimum size of every part but the last in a multipart upload.
+	MinPartSize int64
+	// MaxParts is the maximum number of parts in a multipart upload.
+	MaxParts int
+	// MaxBatchDelete is the maximum number of objects in a single DeleteObjects call.
+	MaxBatchDelete int
+}
+
+// DefaultLimits returns the limits of a STOR server with the default configuration. They are client-side
+// defaults and are not checked against the server.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxKeyLength:   1024,
+		MaxPartSize:    5 << 30,
+		MinPartSize:    5 << 20,
+		MaxParts:       10000,
+		MaxBatchDelete: 1000,
+	}
+}
+
+// withDefaults returns the limits with every field that is not positive set to its default.
+func (l Limits) withDefaults() Limits {
+	d := DefaultLimits()
+	if l.MaxKeyLength <= 0 {
+		l.MaxKeyLength = d.MaxKeyLength
+	}
+	if l.MaxPartSize <= 0 {
+		l.MaxPartSize = d.MaxPartSize
+	}
+	if l.MinPartSize <= 0 {
+		l.MinPartSize = d.MinPartSize
+	}
+	if l.MaxParts <= 0 {
+		l.MaxParts = d.MaxParts
+	}
+	if l.MaxBatchDelete <= 0 {
+		l.MaxBatchDelete = d.MaxBatchDelete
+	}
+	return l
+}
+
+func (l Limits) validateKey(key string) error {
+	if len(key) > l.MaxKeyLength {
+		return fmt.Errorf("%w: %d bytes exceeds the maximum of %d", ErrKeyTooLong, len(key), l.MaxKeyLength)
+	}
+	return nil
+}
+
+func (l Limits) validatePart(partNumber int, size int64) error {
+	if partNumber < 1 || partNumber > l.MaxParts {
+		return fmt.Errorf("%w: %d is not between 1 and %d", ErrInvalidPartNumber, partNumber, l.MaxParts)
+	}
+	if size > l.MaxPartSize {
+		return fmt.Errorf("%w: %d bytes exceeds the maximum of %d", ErrPartTooLarge, size, l.MaxPartSize)
+	}
+	return nil
+}
+
+func (l Limits) validateParts(parts int) error {
+	if parts > l.MaxParts {
+		return fmt.Errorf("%w: %d exceeds the maximum of %d", ErrTooManyParts, parts, l.MaxParts)
+	}
+	return nil
+}
+
+func (l Limits) validateBatchDelete(objects int) error {
+	if objects > l.MaxBatchDelete {
+		return fmt.Errorf("%w: %d exceeds the maximum of %d per batch", ErrTooManyObjects, objects, l.MaxBatchDelete)
+	}
+	return nil
+}
+
+// partSizeFor returns the smallest part size that uploads size bytes within MaxParts parts.
+func (l Limits) partSizeFor(size int64) int64 {
+	partSize := l.MinPartSize
+	if l.MaxParts > 0 {
+		if minSize := (size + int64(l.MaxParts) - 1) / int64(l.MaxParts); minSize > partSize {
+			partSize = minSize
+		}
+	}
+	return partSize
+}
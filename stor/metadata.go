@@ -0,0 +1,35 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"net/http"
+	"strings"
+)
+
+// metadataHeaderPrefix is the prefix of the headers that carry user-defined object metadata.
+// Metadata keys are case-insensitive and are returned in lower case.
+const metadataHeaderPrefix = "Stor-Meta-"
+
+func setMetadataHeader(header http.Header, metadata map[string]string) {
+	for k, v := range metadata {
+		header.Set(metadataHeaderPrefix+k, v)
+	}
+}
+
+// metadataFromHeader returns the user-defined metadata of the header or nil if there is none.
+func metadataFromHeader(header http.Header) map[string]string {
+	var metadata map[string]string
+	for k, v := range header {
+		if len(v) == 0 || !strings.HasPrefix(k, metadataHeaderPrefix) {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[strings.ToLower(strings.TrimPrefix(k, metadataHeaderPrefix))] = v[0]
+	}
+	return metadata
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+)
+
+// MigrationClient mirrors writes to two STOR endpoints during a live migration.
+//
+// The old endpoint is the source of truth: its results are returned, and its errors fail the call.
+// Errors of the new endpoint are reported through OnMirrorError and do not fail the call.
+// Reads go to the preferred endpoint and fall back to the other one if the object cannot be found there.
+type MigrationClient struct {
+	old         *Client
+	new         *Client
+	readFromNew bool
+	// OnMirrorError is called if a write to the new endpoint fails.
+	OnMirrorError func(operation string, err error)
+}
+
+// NewMigrationClient creates a MigrationClient. If readFromNew is true, reads prefer the new endpoint.
+func NewMigrationClient(oldClient, newClient *Client, readFromNew bool) *MigrationClient {
+	return &MigrationClient{
+		old:         oldClient,
+		new:         newClient,
+		readFromNew: readFromNew,
+	}
+}
+
+func (m *MigrationClient) mirrorError(operation string, err error) {
+	if err != nil && m.OnMirrorError != nil {
+		m.OnMirrorError(operation, err)
+	}
+}
+
+// CreateObject creates the object on both endpoints, streaming the data to both at the same time.
+func (m *MigrationClient) CreateObject(ctx context.Context, cmd CreateObjectCommand) (*CreateObjectResult, error) {
+	if cmd.Data == nil {
+		res, err := m.old.CreateObject(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		_, mirrorErr := m.new.CreateObject(ctx, cmd)
+		m.mirrorError("CreateObject", mirrorErr)
+		return res, nil
+	}
+
+	pr, pw := io.Pipe()
+	mirrorCmd := cmd
+	mirrorCmd.Data = pr
+	mirrorDone := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			pr.CloseWithError(err)
+			mirrorDone <- err
+		}()
+		defer recoverPanic(m.new.logger, &err)
+		_, err = m.new.CreateObject(ctx, mirrorCmd)
+	}()
+
+	// errors of the mirror must not fail the upload to the old endpoint
+	tee := io.TeeReader(cmd.Data, &mirrorWriter{w: pw})
+	cmd.Data = tee
+	res, err := m.old.CreateObject(ctx, cmd)
+	if err == nil {
+		// the transport may not consume the body completely, make sure the mirror receives all data
+		_, err = io.Copy(io.Discard, tee)
+	}
+	pw.CloseWithError(err)
+	m.mirrorError("CreateObject", <-mirrorDone)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CopyObject copies the object on both endpoints.
+func (m *MigrationClient) CopyObject(ctx context.Context, cmd CopyObjectCommand) (*CreateObjectResult, error) {
+	res, err := m.old.CopyObject(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	_, mirrorErr := m.new.CopyObject(ctx, cmd)
+	m.mirrorError("CopyObject", mirrorErr)
+	return res, nil
+}
+
+// DeleteObjects deletes the objects on both endpoints.
+func (m *MigrationClient) DeleteObjects(ctx context.Context, cmd DeleteObjectsCommand) (*DeleteObjectsResult, error) {
+	res, err := m.old.DeleteObjects(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	_, mirrorErr := m.new.DeleteObjects(ctx, cmd)
+	m.mirrorError("DeleteObjects", mirrorErr)
+	return res, nil
+}
+
+func (m *MigrationClient) readClients() (*Client, *Client) {
+	if m.readFromNew {
+		return m.new, m.old
+	}
+	return m.old, m.new
+}
+
+// ReadObject reads the object from the preferred endpoint, falling back to the other one if it cannot be found.
+func (m *MigrationClient) ReadObject(ctx context.Context, bucket, key string) (*ReadObjectResult, error) {
+	return m.ReadObjectWithOptions(ctx, ReadObjectCommand{Bucket: bucket, Key: key})
+}
+
+// ReadObjectWithOptions reads the object from the preferred endpoint, falling back to the other one if it cannot
+// be found.
+func (m *MigrationClient) ReadObjectWithOptions(ctx context.Context, cmd ReadObjectCommand) (*ReadObjectResult, error) {
+	preferred, fallback := m.readClients()
+	res, err := preferred.ReadObjectWithOptions(ctx, cmd)
+	if errors.Is(err, ErrObjectNotFound) {
+		return fallback.ReadObjectWithOptions(ctx, cmd)
+	}
+	return res, err
+}
+
+// HeadObject reads the metadata from the preferred endpoint, falling back to the other one if it cannot be found.
+func (m *MigrationClient) HeadObject(ctx context.Context, cmd HeadObjectCommand) (*HeadObjectResult, error) {
+	preferred, fallback := m.readClients()
+	res, err := preferred.HeadObject(ctx, cmd)
+	if errors.Is(err, ErrObjectNotFound) {
+		return fallback.HeadObject(ctx, cmd)
+	}
+	return res, err
+}
+
+// DriftReport lists the differences of a bucket between the old and the new endpoint.
+type DriftReport struct {
+	// MissingInNew contains the keys that only exist on the old endpoint.
+	MissingInNew []string
+	// MissingInOld contains the keys that only exist on the new endpoint.
+	MissingInOld []string
+	// SizeMismatch contains the keys whose size differs between the endpoints.
+	SizeMismatch []string
+}
+
+// InSync returns true if no drift was found.
+func (r *DriftReport) InSync() bool {
+	return len(r.MissingInNew) == 0 && len(r.MissingInOld) == 0 && len(r.SizeMismatch) == 0
+}
+
+// Drift compares the listings of a bucket on both endpoints.
+func (m *MigrationClient) Drift(ctx context.Context, bucket string) (*DriftReport, error) {
+	newSizes := make(map[string]int64)
+	if err := m.new.walkObjects(ctx, ListObjectsCommand{Bucket: bucket}, func(page *ListObjectsResult) error {
+		for _, o := range page.Objects {
+			newSizes[o.Key] = o.Size
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{
+		MissingInNew: make([]string, 0),
+		MissingInOld: make([]string, 0),
+		SizeMismatch: make([]string, 0),
+	}
+	if err := m.old.walkObjects(ctx, ListObjectsCommand{Bucket: bucket}, func(page *ListObjectsResult) error {
+		for _, o := range page.Objects {
+			size, ok := newSizes[o.Key]
+			if !ok {
+				report.MissingInNew = append(report.MissingInNew, o.Key)
+				continue
+			}
+			delete(newSizes, o.Key)
+			if size != o.Size {
+				report.SizeMismatch = append(report.SizeMismatch, o.Key)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for key := range newSizes {
+		report.MissingInOld = append(report.MissingInOld, key)
+	}
+	sort.Strings(report.MissingInOld)
+	return report, nil
+}
+
+// mirrorWriter writes to the mirror until the first error and then discards all data,
+// so that a failing mirror does not fail the primary upload.
+type mirrorWriter struct {
+	w      io.Writer
+	failed bool
+}
+
+func (w *mirrorWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		if _, err := w.w.Write(p); err != nil {
+			w.failed = true
+		}
+	}
+	return len(p), nil
+}
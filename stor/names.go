@@ -0,0 +1,103 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidBucketName = fmt.Errorf("invalid bucket name")
+	ErrInvalidKey        = fmt.Errorf("invalid key")
+)
+
+// BucketName is the name of a bucket.
+type BucketName string
+
+// NewBucketName validates a bucket name. Bucket names are 3 to 63 characters long, consist of lower case
+// letters, digits, dots and hyphens, and start and end with a letter or digit.
+func NewBucketName(name string) (BucketName, error) {
+	if len(name) < 3 || len(name) > 63 {
+		return "", fmt.Errorf("%w: %q must be 3 to 63 characters long", ErrInvalidBucketName, name)
+	}
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		alnum := (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9')
+		if !alnum && ((ch != '.' && ch != '-') || i == 0 || i == len(name)-1) {
+			return "", fmt.Errorf("%w: %q contains invalid character %q at %d", ErrInvalidBucketName, name, ch, i)
+		}
+	}
+	return BucketName(name), nil
+}
+
+func (b BucketName) String() string {
+	return string(b)
+}
+
+// Key is the key of an object.
+type Key string
+
+// NewKey validates an object key. Keys must be valid UTF-8, must not be empty, must not start with "/"
+// and must not be longer than DefaultLimits().MaxKeyLength bytes.
+func NewKey(key string) (Key, error) {
+	if key == "" {
+		return "", fmt.Errorf("%w: key must not be empty", ErrInvalidKey)
+	}
+	if strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("%w: %q must not start with /", ErrInvalidKey, key)
+	}
+	if !utf8.ValidString(key) {
+		return "", fmt.Errorf("%w: %q is not valid UTF-8", ErrInvalidKey, key)
+	}
+	if maxLen := DefaultLimits().MaxKeyLength; len(key) > maxLen {
+		return "", fmt.Errorf("%w: %d bytes exceeds the maximum of %d", ErrInvalidKey, len(key), maxLen)
+	}
+	return Key(key), nil
+}
+
+// JoinKey joins a prefix and path elements with "/", ignoring empty elements and duplicate separators.
+func JoinKey(prefix Key, elem ...string) Key {
+	parts := make([]string, 0, len(elem)+1)
+	for _, p := range append([]string{string(prefix)}, elem...) {
+		p = strings.Trim(p, "/")
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return Key(strings.Join(parts, "/"))
+}
+
+func (k Key) String() string {
+	return string(k)
+}
+
+// Ext returns the file name extension of the key, including the dot, e.g. ".json".
+func (k Key) Ext() string {
+	return path.Ext(string(k))
+}
+
+// Base returns the last element of the key.
+func (k Key) Base() string {
+	return path.Base(string(k))
+}
+
+// Prefix returns the key up to and including the last "/", or an empty string if the key has no "/".
+func (k Key) Prefix() Key {
+	i := strings.LastIndex(string(k), "/")
+	return k[:i+1]
+}
+
+// HasPrefix reports whether the key begins with prefix.
+func (k Key) HasPrefix(prefix Key) bool {
+	return strings.HasPrefix(string(k), string(prefix))
+}
+
+// TrimPrefix returns the key without the given prefix.
+func (k Key) TrimPrefix(prefix Key) Key {
+	return Key(strings.TrimPrefix(string(k), string(prefix)))
+}
@@ -6,7 +6,6 @@ package stor
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -17,6 +16,13 @@ type CreateNonceCommand struct {
 	Bucket string
 	Key    string
 	TTL    time.Duration
+	// ContentType overrides the Content-Type header of downloads through the nonce.
+	ContentType string
+	// ContentDisposition overrides the Content-Disposition header of downloads through the nonce,
+	// e.g. `attachment; filename="report.pdf"`.
+	ContentDisposition string
+	// CacheControl overrides the Cache-Control header of downloads through the nonce.
+	CacheControl string
 }
 
 type CreateNonceResult struct {
@@ -25,11 +31,27 @@ type CreateNonceResult struct {
 }
 
 func (c *Client) CreateNonce(ctx context.Context, cmd CreateNonceCommand) (*CreateNonceResult, error) {
+	if err := c.requireFeature(ctx, FeatureNonces); err != nil {
+		return nil, err
+	}
+	if err := c.checkNoncePolicy(ctx, cmd); err != nil {
+		return nil, err
+	}
 	query := url.Values{}
 	query.Set("nonces", "")
 	query.Set("ttl", strconv.Itoa(int(cmd.TTL.Seconds())))
+	if cmd.ContentType != "" {
+		query.Set("response-content-type", cmd.ContentType)
+	}
+	if cmd.ContentDisposition != "" {
+		query.Set("response-content-disposition", cmd.ContentDisposition)
+	}
+	if cmd.CacheControl != "" {
+		query.Set("response-cache-control", cmd.CacheControl)
+	}
 
 	res, body, err := c.doReq(ctx, R{
+		op:     OperationCreateNonce,
 		method: "POST",
 		path:   objectPath(cmd.Bucket, cmd.Key),
 		query:  query,
@@ -43,7 +65,7 @@ func (c *Client) CreateNonce(ctx context.Context, cmd CreateNonceCommand) (*Crea
 	}
 
 	var result CreateNonceResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
 		return nil, err
 	}
 
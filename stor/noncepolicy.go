@@ -0,0 +1,186 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoncePolicyViolation = fmt.Errorf("nonce policy violation")
+
+// NoncePolicy restricts the nonces that can be created for the objects of a bucket.
+type NoncePolicy struct {
+	// MaxTTL is the longest TTL a nonce may have. If 0, the TTL is not restricted.
+	MaxTTL time.Duration
+	// AllowedPrefixes are the key prefixes nonces may be scoped to. If empty, nonces may be created for every key.
+	AllowedPrefixes []string
+}
+
+type noncePolicyJSON struct {
+	MaxTTL          int64    `json:"maxTtl"`
+	AllowedPrefixes []string `json:"allowedPrefixes"`
+}
+
+func (p NoncePolicy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(noncePolicyJSON{
+		MaxTTL:          int64(p.MaxTTL.Seconds()),
+		AllowedPrefixes: p.AllowedPrefixes,
+	})
+}
+
+func (p *NoncePolicy) UnmarshalJSON(data []byte) error {
+	var v noncePolicyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	p.MaxTTL = time.Duration(v.MaxTTL) * time.Second
+	p.AllowedPrefixes = v.AllowedPrefixes
+	return nil
+}
+
+// check returns ErrNoncePolicyViolation if a nonce for key with the given ttl is not allowed.
+func (p *NoncePolicy) check(key string, ttl time.Duration) error {
+	if p == nil {
+		return nil
+	}
+	if p.MaxTTL > 0 && ttl > p.MaxTTL {
+		return fmt.Errorf("%w: ttl %v exceeds %v", ErrNoncePolicyViolation, ttl, p.MaxTTL)
+	}
+	if len(p.AllowedPrefixes) == 0 {
+		return nil
+	}
+	for _, prefix := range p.AllowedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: key %q is not in an allowed scope", ErrNoncePolicyViolation, key)
+}
+
+// GetBucketNoncePolicy returns the nonce policy of a bucket. A bucket without a policy has a zero policy.
+func (c *Client) GetBucketNoncePolicy(ctx context.Context, bucket string) (*NoncePolicy, error) {
+	if err := c.requireFeature(ctx, FeatureNoncePolicies); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("nonce-policy", "")
+	res, body, err := c.doReq(ctx, R{
+		op:     OperationGetBucketNoncePolicy,
+		method: "GET",
+		path:   bucket,
+		query:  query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == 404 {
+		return nil, ErrBucketNotFound
+	}
+	if res.StatusCode != 200 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to get nonce policy: %v", res.StatusCode)
+	}
+	var policy NoncePolicy
+	if err := c.unmarshalResponse(res, body, &policy); err != nil {
+		return nil, err
+	}
+	c.noncePolicies.set(bucket, &policy)
+	return &policy, nil
+}
+
+// SetBucketNoncePolicy replaces the nonce policy of a bucket. A zero policy removes all restrictions.
+func (c *Client) SetBucketNoncePolicy(ctx context.Context, bucket string, policy NoncePolicy) error {
+	if err := c.requireFeature(ctx, FeatureNoncePolicies); err != nil {
+		return err
+	}
+	body, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("nonce-policy", "")
+	res, _, err := c.doReq(ctx, R{
+		op:          OperationPutBucketNoncePolicy,
+		method:      "PUT",
+		path:        bucket,
+		query:       query,
+		contentType: "application/json",
+		body:        bytes.NewReader(body),
+	})
+	if err != nil {
+		return err
+	}
+	if res.StatusCode == 404 {
+		return ErrBucketNotFound
+	}
+	if res.StatusCode != 204 {
+		//TODO: map error
+		return fmt.Errorf("unable to set nonce policy: %v", res.StatusCode)
+	}
+	c.noncePolicies.set(bucket, &policy)
+	return nil
+}
+
+// checkNoncePolicy enforces the nonce policy of the bucket if enforcement is enabled.
+// Policies are fetched once and cached for the configured duration.
+func (c *Client) checkNoncePolicy(ctx context.Context, cmd CreateNonceCommand) error {
+	if c.noncePolicies == nil {
+		return nil
+	}
+	policy, ok := c.noncePolicies.get(cmd.Bucket)
+	if !ok {
+		var err error
+		if policy, err = c.GetBucketNoncePolicy(ctx, cmd.Bucket); err != nil {
+			return fmt.Errorf("unable to get nonce policy: %w", err)
+		}
+	}
+	return policy.check(cmd.Key, cmd.TTL)
+}
+
+// noncePolicyCache caches the nonce policies of buckets.
+type noncePolicyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]noncePolicyCacheEntry
+}
+
+type noncePolicyCacheEntry struct {
+	policy    *NoncePolicy
+	expiresAt time.Time
+}
+
+func newNoncePolicyCache(ttl time.Duration) *noncePolicyCache {
+	return &noncePolicyCache{
+		ttl:     ttl,
+		entries: make(map[string]noncePolicyCacheEntry),
+	}
+}
+
+func (c *noncePolicyCache) get(bucket string) (*NoncePolicy, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[bucket]
+	if !ok || time.Now().After(e.expiresAt) {
+		delete(c.entries, bucket)
+		return nil, false
+	}
+	return e.policy, true
+}
+
+func (c *noncePolicyCache) set(bucket string, policy *NoncePolicy) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[bucket] = noncePolicyCacheEntry{policy: policy, expiresAt: time.Now().Add(c.ttl)}
+}
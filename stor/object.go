@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,19 +18,30 @@ import (
 )
 
 type Object struct {
-	Key         string    `json:"key"`
-	ContentType string    `json:"contentType"`
-	Size        int64     `json:"size"`
-	CreatedAt   time.Time `json:"createdAt"`
+	Key         string            `json:"key"`
+	ContentType string            `json:"contentType"`
+	Size        int64             `json:"size"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	// ETag of the object. It is only set if the listing was requested with IncludeMetadata.
+	ETag string `json:"etag,omitempty"`
+	// StorageClass is the storage tier of the object. It is empty if the server does not support storage classes.
+	StorageClass StorageClass `json:"storageClass,omitempty"`
 }
 
 type ObjectReference struct {
 	Key string `json:"key"`
+	// VersionId deletes a specific version of the object in a versioned bucket.
+	VersionId string `json:"versionId,omitempty"`
+	// IfGenerationMatch deletes the object only if its current generation matches. If 0, it is not checked.
+	IfGenerationMatch int64 `json:"ifGenerationMatch,omitempty"`
+	// IfGenerationNotMatch deletes the object only if its current generation does not match. If 0, it is not checked.
+	IfGenerationNotMatch int64 `json:"ifGenerationNotMatch,omitempty"`
 }
 
 type Error struct {
-	Code    string
-	Message string
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 type CreateObjectCommand struct {
@@ -37,95 +49,436 @@ type CreateObjectCommand struct {
 	Key         string
 	ContentType string
 	Data        io.Reader
+	// ContentLength is the size of Data in bytes. It is optional if the size of Data can be determined.
+	ContentLength int64
 	// IfNoneMatch uploads the object only if the object key name does not already exist in the bucket
 	IfNoneMatch bool
+	// IfGenerationMatch writes the object only if its current generation matches. If 0, it is not checked.
+	// Otherwise, ErrPreconditionFailed is returned.
+	IfGenerationMatch int64
+	// IfGenerationNotMatch writes the object only if its current generation does not match. If 0, it is not checked.
+	IfGenerationNotMatch int64
+	// CacheControl is served with downloads of the object.
+	CacheControl string
+	// Metadata is user-defined metadata that is stored with the object.
+	// Keys are case-insensitive and are returned in lower case.
+	Metadata map[string]string
+	// ContentEncoding is the encoding of Data, e.g. "gzip" for pre-compressed assets.
+	ContentEncoding string
+	// ContentMD5 is the base64 encoded MD5 digest of Data. The server rejects the upload if the digest does not match.
+	ContentMD5 string
+	// ChecksumSHA256 is the base64 encoded SHA-256 digest of Data. The server rejects the upload if the digest
+	// does not match.
+	ChecksumSHA256 string
+	// ChecksumAlgorithm makes the client compute a checksum of Data while uploading it. The checksum is sent
+	// as a trailer and the server rejects the upload if it does not match.
+	ChecksumAlgorithm ChecksumAlgorithm
+	// Expires makes the server delete the object once the time has passed.
+	Expires time.Time
+	// StorageClass is the storage tier of the object. Defaults to the storage class of the server.
+	StorageClass StorageClass
 }
 
 type CreateObjectResult struct {
 	ETag string `json:"etag"`
+	// ChecksumAlgorithm and Checksum are the algorithm and base64 encoded checksum computed by the client,
+	// if a ChecksumAlgorithm was requested.
+	ChecksumAlgorithm ChecksumAlgorithm `json:"checksumAlgorithm,omitempty"`
+	Checksum          string            `json:"checksum,omitempty"`
+	// VersionId is the version of the created object in a versioned bucket.
+	VersionId string `json:"versionId,omitempty"`
+	// Generation is the generation of the created object, 0 if the server does not report generations.
+	Generation int64 `json:"generation,omitempty"`
 }
 
 func (c *Client) CreateObject(ctx context.Context, cmd CreateObjectCommand) (*CreateObjectResult, error) {
+	if err := c.limits.validateKey(cmd.Key); err != nil {
+		return nil, err
+	}
 	header := http.Header{}
 	if cmd.IfNoneMatch {
 		header.Set("If-None-Match", "*")
 	}
+	if cmd.CacheControl != "" {
+		header.Set("Cache-Control", cmd.CacheControl)
+	}
+	setMetadataHeader(header, cmd.Metadata)
+	if cmd.ContentEncoding != "" {
+		header.Set("Content-Encoding", cmd.ContentEncoding)
+	}
+	if cmd.ContentMD5 != "" {
+		header.Set("Content-MD5", cmd.ContentMD5)
+	}
+	if cmd.ChecksumSHA256 != "" {
+		if cmd.ChecksumAlgorithm == ChecksumSHA256 {
+			return nil, fmt.Errorf("%w: ChecksumSHA256 and ChecksumAlgorithm SHA256 are mutually exclusive", ErrInvalidArgument)
+		}
+		header.Set("Stor-Checksum-Sha256", cmd.ChecksumSHA256)
+	}
+	if err := setExpiresHeader(header, cmd.Expires); err != nil {
+		return nil, err
+	}
+	if err := c.setStorageClassHeader(ctx, header, cmd.StorageClass); err != nil {
+		return nil, err
+	}
+	if err := c.setGenerationHeaders(ctx, header, cmd.IfGenerationMatch, cmd.IfGenerationNotMatch); err != nil {
+		return nil, err
+	}
+	body := cmd.Data
+	contentLength := cmd.ContentLength
+	var trailer http.Header
+	var checksum *checksumReader
+	if cmd.ChecksumAlgorithm != "" {
+		if contentLength == 0 {
+			contentLength = readerLen(cmd.Data)
+		}
+		trailer = http.Header{}
+		var err error
+		if checksum, err = newChecksumReader(cmd.Data, cmd.ChecksumAlgorithm, header, trailer); err != nil {
+			return nil, err
+		}
+		body = checksum
+	}
 	res, _, err := c.doReq(ctx, R{
-		method:      "PUT",
-		path:        objectPath(cmd.Bucket, cmd.Key),
-		header:      header,
-		contentType: cmd.ContentType,
-		body:        cmd.Data,
+		op:            OperationCreateObject,
+		method:        "PUT",
+		path:          objectPath(cmd.Bucket, cmd.Key),
+		header:        header,
+		contentType:   cmd.ContentType,
+		body:          body,
+		contentLength: contentLength,
+		trailer:       trailer,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if res.StatusCode == 412 {
+		return nil, ErrPreconditionFailed
+	}
 	if res.StatusCode != 204 {
 		//TODO: map error
 		return nil, fmt.Errorf("unable to create object: %v", res.StatusCode)
 	}
 
-	return &CreateObjectResult{
-		ETag: res.Header.Get("ETag"),
+	result := &CreateObjectResult{
+		ETag:       res.Header.Get("ETag"),
+		VersionId:  res.Header.Get(versionIdHeader),
+		Generation: generationFromHeader(res.Header),
+	}
+	if checksum != nil {
+		result.ChecksumAlgorithm = checksum.algorithm
+		result.Checksum = checksum.sum
+	}
+	return result, nil
+}
+
+type AppendObjectCommand struct {
+	Bucket      string
+	Key         string
+	ContentType string
+	// Offset is the current size of the object. The data is only appended if the object has exactly this size,
+	// which protects against concurrent appends. An offset of 0 creates the object if it does not exist.
+	Offset int64
+	Data   io.Reader
+	// ContentLength is the size of the data in bytes. It is optional if the size of Data can be determined.
+	ContentLength int64
+}
+
+type AppendObjectResult struct {
+	ETag string
+	// NextOffset is the offset for the next append, i.e. the size of the object after the append.
+	NextOffset int64
+}
+
+// AppendObject appends data to an object.
+// If the object does not have the size given in Offset, the method returns ErrPreconditionFailed.
+func (c *Client) AppendObject(ctx context.Context, cmd AppendObjectCommand) (*AppendObjectResult, error) {
+	if err := c.limits.validateKey(cmd.Key); err != nil {
+		return nil, err
+	}
+	if cmd.Offset < 0 || cmd.ContentLength < 0 {
+		return nil, fmt.Errorf("%w: Offset and ContentLength must not be negative", ErrInvalidArgument)
+	}
+	query := url.Values{}
+	query.Set("append", "")
+	query.Set("offset", strconv.FormatInt(cmd.Offset, 10))
+	length := cmd.ContentLength
+	if length == 0 {
+		length = readerLen(cmd.Data)
+	}
+	body := &countingReader{r: cmd.Data}
+	if cmd.Data == nil {
+		length = 0
+		body.r = http.NoBody
+	}
+	res, _, err := c.doReq(ctx, R{
+		op:            OperationAppendObject,
+		method:        "POST",
+		path:          objectPath(cmd.Bucket, cmd.Key),
+		query:         query,
+		contentType:   cmd.ContentType,
+		contentLength: length,
+		body:          body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == 412 {
+		return nil, ErrPreconditionFailed
+	}
+	if res.StatusCode != 200 && res.StatusCode != 204 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to append to object: %v", res.StatusCode)
+	}
+
+	return &AppendObjectResult{
+		ETag:       res.Header.Get("ETag"),
+		NextOffset: cmd.Offset + body.n,
 	}, nil
 }
 
 type CopyObjectCommand struct {
 	// The bucket to create the object in
 	Bucket string
+	// The bucket of the object to copy. Defaults to Bucket.
+	SourceBucket string
 	// The key of the object to copy
 	SourceKey string
+	// SourceVersionId copies a specific version of the source object in a versioned bucket.
+	SourceVersionId string
+	// SourceIfMatch copies the object only if the ETag of the source object matches.
+	// Otherwise, ErrPreconditionFailed is returned.
+	SourceIfMatch string
 	// The key of the object to be created or updated
 	DestKey string
 	// IfNoneMatch uploads the object only if the object key name does not already exist in the bucket
 	IfNoneMatch bool
+	// IfGenerationMatch writes the object only if its current generation matches. If 0, it is not checked.
+	// Otherwise, ErrPreconditionFailed is returned.
+	IfGenerationMatch int64
+	// IfGenerationNotMatch writes the object only if its current generation does not match. If 0, it is not checked.
+	IfGenerationNotMatch int64
+	// MetadataDirective defines whether the content type and metadata are copied from the source object,
+	// which is the default, or replaced with ContentType and Metadata.
+	MetadataDirective MetadataDirective
+	// ContentType of the copy. Requires MetadataDirectiveReplace.
+	ContentType string
+	// Metadata of the copy. Requires MetadataDirectiveReplace.
+	Metadata map[string]string
+	// StorageClass of the copy. Defaults to the storage class of the server.
+	StorageClass StorageClass
 }
 
-// CopyObject copies an object. If the destination object already exists, it will be updated.
+// MetadataDirective defines how metadata is handled when copying an object.
+type MetadataDirective string
+
+const (
+	// MetadataDirectiveCopy copies the content type and metadata of the source object.
+	MetadataDirectiveCopy MetadataDirective = "COPY"
+	// MetadataDirectiveReplace replaces the content type and metadata with the values given in the command.
+	MetadataDirectiveReplace MetadataDirective = "REPLACE"
+)
+
+// CopyObject copies an object on the server, within a bucket or across buckets.
+// If the destination object already exists, it will be updated.
+// If the source object cannot be found, the method returns ErrObjectNotFound.
 func (c *Client) CopyObject(ctx context.Context, cmd CopyObjectCommand) (*CreateObjectResult, error) {
+	if err := c.limits.validateKey(cmd.DestKey); err != nil {
+		return nil, err
+	}
+	contentType := ""
 	header := http.Header{}
 	header.Set("Stor-Copy-Source", cmd.SourceKey)
+	if cmd.SourceBucket != "" && cmd.SourceBucket != cmd.Bucket {
+		header.Set("Stor-Copy-Source-Bucket", cmd.SourceBucket)
+	}
+	if cmd.SourceVersionId != "" {
+		if err := c.requireFeature(ctx, FeatureVersioning); err != nil {
+			return nil, err
+		}
+		header.Set("Stor-Copy-Source-Version-Id", cmd.SourceVersionId)
+	}
+	if cmd.SourceIfMatch != "" {
+		header.Set("Stor-Copy-Source-If-Match", cmd.SourceIfMatch)
+	}
+	if err := c.setStorageClassHeader(ctx, header, cmd.StorageClass); err != nil {
+		return nil, err
+	}
+	if err := c.setGenerationHeaders(ctx, header, cmd.IfGenerationMatch, cmd.IfGenerationNotMatch); err != nil {
+		return nil, err
+	}
 	if cmd.IfNoneMatch {
 		header.Set("If-None-Match", "*")
 	}
+	switch cmd.MetadataDirective {
+	case "", MetadataDirectiveCopy:
+		if cmd.ContentType != "" || len(cmd.Metadata) > 0 {
+			return nil, fmt.Errorf("%w: ContentType and Metadata require MetadataDirectiveReplace", ErrInvalidArgument)
+		}
+	case MetadataDirectiveReplace:
+		header.Set("Stor-Metadata-Directive", string(cmd.MetadataDirective))
+		contentType = cmd.ContentType
+		setMetadataHeader(header, cmd.Metadata)
+	default:
+		return nil, fmt.Errorf("%w: unknown metadata directive %q", ErrInvalidArgument, cmd.MetadataDirective)
+	}
 	res, _, err := c.doReq(ctx, R{
-		method: "PUT",
-		path:   objectPath(cmd.Bucket, cmd.DestKey),
-		header: header,
+		op:          OperationCopyObject,
+		method:      "PUT",
+		path:        objectPath(cmd.Bucket, cmd.DestKey),
+		header:      header,
+		contentType: contentType,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if res.StatusCode == 404 {
+		return nil, ErrObjectNotFound
+	}
+	if res.StatusCode == 412 {
+		return nil, ErrPreconditionFailed
+	}
 	if res.StatusCode != 204 {
 		//TODO: map error
-		return nil, fmt.Errorf("unable to create object: %v", res.StatusCode)
+		return nil, fmt.Errorf("unable to copy object: %v", res.StatusCode)
 	}
 
 	return &CreateObjectResult{
-		ETag: res.Header.Get("ETag"),
+		ETag:       res.Header.Get("ETag"),
+		VersionId:  res.Header.Get(versionIdHeader),
+		Generation: generationFromHeader(res.Header),
 	}, nil
 }
 
+type MoveObjectCommand struct {
+	// The bucket to move the object to
+	Bucket string
+	// The bucket of the object to move. Defaults to Bucket.
+	SourceBucket string
+	// The key of the object to move
+	SourceKey string
+	// SourceIfMatch moves the object only if the ETag of the source object matches.
+	// If empty, the move is pinned to the ETag the source has when the move starts.
+	SourceIfMatch string
+	// The new key of the object
+	DestKey string
+	// IfNoneMatch moves the object only if the destination key does not already exist in the bucket
+	IfNoneMatch bool
+	// MetadataDirective defines whether the content type and metadata are kept, which is the default,
+	// or replaced with ContentType and Metadata.
+	MetadataDirective MetadataDirective
+	// ContentType of the moved object. Requires MetadataDirectiveReplace.
+	ContentType string
+	// Metadata of the moved object. Requires MetadataDirectiveReplace.
+	Metadata map[string]string
+	// StorageClass of the moved object. Defaults to the storage class of the server.
+	StorageClass StorageClass
+}
+
+// MoveObject moves an object to a new key by copying it on the server and deleting the source.
+// The copy is pinned to the ETag of the source, so a source that changes during the move fails with
+// ErrPreconditionFailed.
+//
+// If the source cannot be deleted, the copy is only deleted again if the source is confirmed to be unchanged.
+// If the state of the source is unknown, e.g. because the deletion timed out, the copy is kept and the returned
+// error names both keys.
+func (c *Client) MoveObject(ctx context.Context, cmd MoveObjectCommand) (*CreateObjectResult, error) {
+	sourceBucket := cmd.SourceBucket
+	if sourceBucket == "" {
+		sourceBucket = cmd.Bucket
+	}
+	if sourceBucket == cmd.Bucket && cmd.SourceKey == cmd.DestKey {
+		return nil, fmt.Errorf("%w: source and destination of a move must differ", ErrInvalidArgument)
+	}
+	etag := cmd.SourceIfMatch
+	if etag == "" {
+		source, err := c.HeadObject(ctx, HeadObjectCommand{Bucket: sourceBucket, Key: cmd.SourceKey})
+		if err != nil {
+			return nil, err
+		}
+		etag = source.ETag
+	}
+	result, err := c.CopyObject(ctx, CopyObjectCommand{
+		Bucket:            cmd.Bucket,
+		SourceBucket:      sourceBucket,
+		SourceKey:         cmd.SourceKey,
+		SourceIfMatch:     etag,
+		DestKey:           cmd.DestKey,
+		IfNoneMatch:       cmd.IfNoneMatch,
+		MetadataDirective: cmd.MetadataDirective,
+		ContentType:       cmd.ContentType,
+		Metadata:          cmd.Metadata,
+		StorageClass:      cmd.StorageClass,
+	})
+	if err != nil {
+		return nil, err
+	}
+	deleteErr := c.deleteObject(ctx, sourceBucket, cmd.SourceKey)
+	if deleteErr == nil {
+		return result, nil
+	}
+	source, err := c.HeadObject(ctx, HeadObjectCommand{Bucket: sourceBucket, Key: cmd.SourceKey})
+	if errors.Is(err, ErrObjectNotFound) {
+		// the source was deleted although the deletion reported an error
+		return result, nil
+	}
+	if err != nil || source.ETag != etag {
+		return nil, fmt.Errorf("unable to move object: source %s/%s may still exist and copy %s/%s was kept: %w",
+			sourceBucket, cmd.SourceKey, cmd.Bucket, cmd.DestKey, deleteErr)
+	}
+	if rollbackErr := c.deleteObject(ctx, cmd.Bucket, cmd.DestKey); rollbackErr != nil {
+		return nil, fmt.Errorf("unable to move object: %v (unable to delete copy %s/%s: %v)", deleteErr, cmd.Bucket, cmd.DestKey, rollbackErr)
+	}
+	return nil, fmt.Errorf("unable to move object: %w", deleteErr)
+}
+
 type CreateMultipartUploadCommand struct {
 	Bucket      string
 	Key         string
 	ContentType string
+	// Metadata is user-defined metadata that is stored with the object.
+	Metadata map[string]string
+	// ContentEncoding is the encoding of the uploaded data, e.g. "gzip" for pre-compressed assets.
+	ContentEncoding string
+	// StorageClass is the storage tier of the object. Defaults to the storage class of the server.
+	StorageClass StorageClass
 }
 
 type CreateMultipartUploadResult struct {
-	Bucket   string
-	Key      string
-	UploadId string
+	Bucket   string `json:"bucket"`
+	Key      string `json:"key"`
+	UploadId string `json:"uploadId"`
+}
+
+func (r *CreateMultipartUploadResult) UnmarshalJSON(data []byte) error {
+	return unmarshalStringFields(data, map[string]*string{
+		"bucket":   &r.Bucket,
+		"key":      &r.Key,
+		"uploadid": &r.UploadId,
+	})
 }
 
 // CreateMultipartUpload initiates a multipart upload.
 func (c *Client) CreateMultipartUpload(ctx context.Context, cmd CreateMultipartUploadCommand) (*CreateMultipartUploadResult, error) {
+	if err := c.limits.validateKey(cmd.Key); err != nil {
+		return nil, err
+	}
 	query := url.Values{}
 	query.Set("uploads", "")
+	header := http.Header{}
+	setMetadataHeader(header, cmd.Metadata)
+	if cmd.ContentEncoding != "" {
+		header.Set("Content-Encoding", cmd.ContentEncoding)
+	}
+	if err := c.setStorageClassHeader(ctx, header, cmd.StorageClass); err != nil {
+		return nil, err
+	}
 	res, body, err := c.doReq(ctx, R{
+		op:          OperationCreateMultipartUpload,
 		method:      "POST",
 		path:        objectPath(cmd.Bucket, cmd.Key),
 		query:       query,
+		header:      header,
 		contentType: cmd.ContentType,
 	})
 	if err != nil {
@@ -137,7 +490,7 @@ func (c *Client) CreateMultipartUpload(ctx context.Context, cmd CreateMultipartU
 	}
 
 	var result CreateMultipartUploadResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
 		return nil, err
 	}
 
@@ -145,28 +498,57 @@ func (c *Client) CreateMultipartUpload(ctx context.Context, cmd CreateMultipartU
 }
 
 type UploadPartCommand struct {
-	Bucket        string
-	Key           string
-	UploadId      string
-	PartNumber    int
-	Data          io.Reader
-	ContentLength int
+	Bucket     string
+	Key        string
+	UploadId   string
+	PartNumber int
+	Data       io.Reader
+	// ContentLength is the size of the part in bytes.
+	ContentLength int64
+	// ChecksumAlgorithm makes the client compute a checksum of Data while uploading it. The checksum is sent
+	// as a trailer and the server rejects the part if it does not match.
+	ChecksumAlgorithm ChecksumAlgorithm
 }
 
 type UploadPartResponse struct {
 	ETag string
+	// ChecksumAlgorithm and Checksum are the algorithm and base64 encoded checksum computed by the client,
+	// if a ChecksumAlgorithm was requested.
+	ChecksumAlgorithm ChecksumAlgorithm
+	Checksum          string
 }
 
 // UploadPart uploads a part in a multipart upload.
 func (c *Client) UploadPart(ctx context.Context, cmd UploadPartCommand) (*UploadPartResponse, error) {
+	if cmd.ContentLength < 0 {
+		return nil, fmt.Errorf("%w: ContentLength must not be negative, got %d", ErrInvalidArgument, cmd.ContentLength)
+	}
+	if err := c.limits.validatePart(cmd.PartNumber, cmd.ContentLength); err != nil {
+		return nil, err
+	}
 	query := url.Values{}
 	query.Set("upload-id", cmd.UploadId)
 	query.Set("part-number", strconv.Itoa(cmd.PartNumber))
+	body := cmd.Data
+	var header, trailer http.Header
+	var checksum *checksumReader
+	if cmd.ChecksumAlgorithm != "" {
+		header, trailer = http.Header{}, http.Header{}
+		var err error
+		if checksum, err = newChecksumReader(cmd.Data, cmd.ChecksumAlgorithm, header, trailer); err != nil {
+			return nil, err
+		}
+		body = checksum
+	}
 	res, _, err := c.doReq(ctx, R{
+		op:            OperationUploadPart,
 		method:        "PUT",
 		path:          objectPath(cmd.Bucket, cmd.Key),
 		query:         query,
+		header:        header,
+		body:          body,
 		contentLength: cmd.ContentLength,
+		trailer:       trailer,
 	})
 	if err != nil {
 		return nil, err
@@ -176,6 +558,84 @@ func (c *Client) UploadPart(ctx context.Context, cmd UploadPartCommand) (*Upload
 		return nil, fmt.Errorf("unable to upload part: %v", res.StatusCode)
 	}
 
+	result := &UploadPartResponse{
+		ETag: res.Header.Get("ETag"),
+	}
+	if checksum != nil {
+		result.ChecksumAlgorithm = checksum.algorithm
+		result.Checksum = checksum.sum
+	}
+	return result, nil
+}
+
+type UploadPartCopyCommand struct {
+	Bucket     string
+	Key        string
+	UploadId   string
+	PartNumber int
+	// SourceBucket is the bucket of the object to copy from. Defaults to Bucket.
+	SourceBucket string
+	// SourceKey is the key of the object to copy from.
+	SourceKey string
+	// SourceRange copies only the given range of the source object. If nil, the whole object is copied.
+	SourceRange *ByteRange
+	// SourceIfMatch copies the part only if the ETag of the source object matches.
+	SourceIfMatch string
+}
+
+// UploadPartCopy uploads a part in a multipart upload by copying a range of an existing object on the server.
+// If the source object cannot be found, the method returns ErrObjectNotFound.
+func (c *Client) UploadPartCopy(ctx context.Context, cmd UploadPartCopyCommand) (*UploadPartResponse, error) {
+	size := int64(0)
+	if cmd.SourceRange != nil {
+		if err := cmd.SourceRange.validate(); err != nil {
+			return nil, err
+		}
+		if cmd.SourceRange.End >= 0 {
+			size = cmd.SourceRange.End - cmd.SourceRange.Start + 1
+		}
+	}
+	if err := c.limits.validatePart(cmd.PartNumber, size); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("upload-id", cmd.UploadId)
+	query.Set("part-number", strconv.Itoa(cmd.PartNumber))
+	header := http.Header{}
+	header.Set("Stor-Copy-Source", cmd.SourceKey)
+	if cmd.SourceBucket != "" && cmd.SourceBucket != cmd.Bucket {
+		header.Set("Stor-Copy-Source-Bucket", cmd.SourceBucket)
+	}
+	if cmd.SourceRange != nil {
+		header.Set("Stor-Copy-Source-Range", cmd.SourceRange.header())
+	}
+	if cmd.SourceIfMatch != "" {
+		header.Set("Stor-Copy-Source-If-Match", cmd.SourceIfMatch)
+	}
+	res, _, err := c.doReq(ctx, R{
+		op:     OperationUploadPartCopy,
+		method: "PUT",
+		path:   objectPath(cmd.Bucket, cmd.Key),
+		query:  query,
+		header: header,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == 404 {
+		return nil, ErrObjectNotFound
+	}
+	if res.StatusCode == 412 {
+		return nil, ErrPreconditionFailed
+	}
+	if res.StatusCode == 416 {
+		return nil, ErrInvalidRange
+	}
+	if res.StatusCode != 200 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to copy part: %v", res.StatusCode)
+	}
+
 	return &UploadPartResponse{
 		ETag: res.Header.Get("ETag"),
 	}, nil
@@ -184,6 +644,9 @@ func (c *Client) UploadPart(ctx context.Context, cmd UploadPartCommand) (*Upload
 type PartReference struct {
 	ETag       string `json:"etag"`
 	PartNumber int    `json:"partNumber"`
+	// Checksum is the base64 encoded checksum of the part, as returned in UploadPartResponse.Checksum.
+	// It is required for every part if the upload is completed with a ChecksumAlgorithm.
+	Checksum string `json:"checksum,omitempty"`
 }
 
 type CompleteMultipartUploadCommand struct {
@@ -192,13 +655,31 @@ type CompleteMultipartUploadCommand struct {
 	UploadId string
 	// IfNoneMatch uploads the object only if the object key name does not already exist in the bucket
 	IfNoneMatch bool
-	Parts       []PartReference
+	// IfGenerationMatch writes the object only if its current generation matches. If 0, it is not checked.
+	// Otherwise, ErrPreconditionFailed is returned.
+	IfGenerationMatch int64
+	// IfGenerationNotMatch writes the object only if its current generation does not match. If 0, it is not checked.
+	IfGenerationNotMatch int64
+	Parts                []PartReference
+	// Expires makes the server delete the object once the time has passed.
+	Expires time.Time
+	// ChecksumAlgorithm sends a composite checksum computed from the checksums of the parts, which must have been
+	// uploaded with the same algorithm. The server rejects the upload if it does not match.
+	ChecksumAlgorithm ChecksumAlgorithm
 }
 
 type CompleteMultipartUploadResult struct {
 	Bucket string `json:"bucket"`
 	Key    string `json:"key"`
 	ETag   string `json:"etag"`
+	// VersionId is the version of the created object in a versioned bucket.
+	VersionId string `json:"versionId,omitempty"`
+	// Generation is the generation of the created object, 0 if the server does not report generations.
+	Generation int64 `json:"generation,omitempty"`
+	// ChecksumAlgorithm and Checksum are the algorithm and composite checksum of the object,
+	// if a ChecksumAlgorithm was requested.
+	ChecksumAlgorithm ChecksumAlgorithm `json:"checksumAlgorithm,omitempty"`
+	Checksum          string            `json:"checksum,omitempty"`
 }
 
 type completeMultipartUploadRequest struct {
@@ -206,12 +687,30 @@ type completeMultipartUploadRequest struct {
 }
 
 func (c *Client) CompleteMultipartUpload(ctx context.Context, cmd CompleteMultipartUploadCommand) (*CompleteMultipartUploadResult, error) {
+	if err := c.limits.validateParts(len(cmd.Parts)); err != nil {
+		return nil, err
+	}
 	query := url.Values{}
 	query.Set("upload-id", cmd.UploadId)
 	header := http.Header{}
 	if cmd.IfNoneMatch {
 		header.Set("If-None-Match", "*")
 	}
+	if err := setExpiresHeader(header, cmd.Expires); err != nil {
+		return nil, err
+	}
+	if err := c.setGenerationHeaders(ctx, header, cmd.IfGenerationMatch, cmd.IfGenerationNotMatch); err != nil {
+		return nil, err
+	}
+	checksum := ""
+	if cmd.ChecksumAlgorithm != "" {
+		var err error
+		if checksum, err = compositeChecksum(cmd.ChecksumAlgorithm, cmd.Parts); err != nil {
+			return nil, err
+		}
+		header.Set(checksumAlgorithmHeader, string(cmd.ChecksumAlgorithm))
+		header.Set(cmd.ChecksumAlgorithm.header(), checksum)
+	}
 	body, err := json.Marshal(completeMultipartUploadRequest{
 		Parts: cmd.Parts,
 	})
@@ -219,6 +718,7 @@ func (c *Client) CompleteMultipartUpload(ctx context.Context, cmd CompleteMultip
 		return nil, err
 	}
 	res, responseBody, err := c.doReq(ctx, R{
+		op:     OperationCompleteMultipartUpload,
 		method: "POST",
 		path:   objectPath(cmd.Bucket, cmd.Key),
 		query:  query,
@@ -228,15 +728,22 @@ func (c *Client) CompleteMultipartUpload(ctx context.Context, cmd CompleteMultip
 	if err != nil {
 		return nil, err
 	}
+	if res.StatusCode == 412 {
+		return nil, ErrPreconditionFailed
+	}
 	if res.StatusCode != 200 {
 		//TODO: map error
 		return nil, fmt.Errorf("unable to complete upload: %v", res.StatusCode)
 	}
 
 	var result CompleteMultipartUploadResult
-	if err := json.Unmarshal(responseBody, &result); err != nil {
+	if err := c.unmarshalResponse(res, responseBody, &result); err != nil {
 		return nil, err
 	}
+	if checksum != "" && result.Checksum == "" {
+		result.ChecksumAlgorithm = cmd.ChecksumAlgorithm
+		result.Checksum = checksum
+	}
 
 	return &result, nil
 }
@@ -251,6 +758,7 @@ func (c *Client) AbortMultipartUpload(ctx context.Context, cmd AbortMultipartUpl
 	query := url.Values{}
 	query.Set("upload-id", cmd.UploadId)
 	res, _, err := c.doReq(ctx, R{
+		op:     OperationAbortMultipartUpload,
 		method: "DELETE",
 		path:   objectPath(cmd.Bucket, cmd.Key),
 		query:  query,
@@ -265,13 +773,171 @@ func (c *Client) AbortMultipartUpload(ctx context.Context, cmd AbortMultipartUpl
 	return nil
 }
 
+// MaxListParts is the maximum number of parts that can be requested in a single ListParts call.
+const MaxListParts = 1000
+
+type ListPartsCommand struct {
+	Bucket   string
+	Key      string
+	UploadId string
+	// PartNumberMarker lists only parts with a higher part number, usually ListPartsResult.NextPartNumberMarker.
+	PartNumberMarker int
+	// MaxParts limits the results to max parts. Defaults to MaxListParts if 0.
+	MaxParts int
+}
+
+type Part struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+}
+
+type ListPartsResult struct {
+	IsTruncated          bool    `json:"isTruncated"`
+	NextPartNumberMarker int     `json:"nextPartNumberMarker"`
+	Parts                []*Part `json:"parts"`
+}
+
+// ListParts lists the parts that have been uploaded for a multipart upload, ordered by part number.
+// If the upload cannot be found, the method returns ErrUploadNotFound.
+func (c *Client) ListParts(ctx context.Context, cmd ListPartsCommand) (*ListPartsResult, error) {
+	if cmd.MaxParts < 0 || cmd.MaxParts > MaxListParts {
+		return nil, fmt.Errorf("%w: MaxParts must be between 0 and %d, got %d", ErrInvalidArgument, MaxListParts, cmd.MaxParts)
+	}
+	query := url.Values{}
+	query.Set("upload-id", cmd.UploadId)
+	if cmd.PartNumberMarker > 0 {
+		query.Set("part-number-marker", strconv.Itoa(cmd.PartNumberMarker))
+	}
+	if cmd.MaxParts > 0 {
+		query.Set("max-parts", strconv.Itoa(cmd.MaxParts))
+	}
+	res, body, err := c.doReq(ctx, R{
+		op:    OperationListParts,
+		path:  objectPath(cmd.Bucket, cmd.Key),
+		query: query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == 404 {
+		return nil, ErrUploadNotFound
+	}
+	if res.StatusCode != 200 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to list parts: %v", res.StatusCode)
+	}
+
+	var result ListPartsResult
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// MaxListUploads is the maximum number of uploads that can be requested in a single ListMultipartUploads call.
+const MaxListUploads = 1000
+
+type ListMultipartUploadsCommand struct {
+	Bucket string
+	Prefix string
+	// KeyMarker and UploadIdMarker continue a listing after the given upload,
+	// usually ListMultipartUploadsResult.NextKeyMarker and NextUploadIdMarker.
+	KeyMarker      string
+	UploadIdMarker string
+	// MaxUploads limits the results to max uploads. Defaults to MaxListUploads if 0.
+	MaxUploads int
+}
+
+type MultipartUpload struct {
+	Key         string    `json:"key"`
+	UploadId    string    `json:"uploadId"`
+	InitiatedAt time.Time `json:"initiatedAt"`
+}
+
+type ListMultipartUploadsResult struct {
+	IsTruncated        bool               `json:"isTruncated"`
+	NextKeyMarker      string             `json:"nextKeyMarker"`
+	NextUploadIdMarker string             `json:"nextUploadIdMarker"`
+	Uploads            []*MultipartUpload `json:"uploads"`
+}
+
+// ListMultipartUploads lists the multipart uploads of a bucket that have been neither completed nor aborted,
+// ordered by key and initiation time.
+func (c *Client) ListMultipartUploads(ctx context.Context, cmd ListMultipartUploadsCommand) (*ListMultipartUploadsResult, error) {
+	if cmd.MaxUploads < 0 || cmd.MaxUploads > MaxListUploads {
+		return nil, fmt.Errorf("%w: MaxUploads must be between 0 and %d, got %d", ErrInvalidArgument, MaxListUploads, cmd.MaxUploads)
+	}
+	query := url.Values{}
+	query.Set("uploads", "")
+	if cmd.Prefix != "" {
+		query.Set("prefix", cmd.Prefix)
+	}
+	if cmd.KeyMarker != "" {
+		query.Set("key-marker", cmd.KeyMarker)
+	}
+	if cmd.UploadIdMarker != "" {
+		query.Set("upload-id-marker", cmd.UploadIdMarker)
+	}
+	if cmd.MaxUploads > 0 {
+		query.Set("max-uploads", strconv.Itoa(cmd.MaxUploads))
+	}
+	res, body, err := c.doReq(ctx, R{
+		op:    OperationListMultipartUploads,
+		path:  cmd.Bucket,
+		query: query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == 404 {
+		return nil, ErrBucketNotFound
+	}
+	if res.StatusCode != 200 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to list multipart uploads: %v", res.StatusCode)
+	}
+
+	var result ListMultipartUploadsResult
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// walkUploads calls fn for every page of the multipart upload listing.
+func (c *Client) walkUploads(ctx context.Context, cmd ListMultipartUploadsCommand, fn func(page *ListMultipartUploadsResult) error) error {
+	for {
+		page, err := c.ListMultipartUploads(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		if !page.IsTruncated || page.NextKeyMarker == "" {
+			return nil
+		}
+		cmd.KeyMarker = page.NextKeyMarker
+		cmd.UploadIdMarker = page.NextUploadIdMarker
+	}
+}
+
+// MaxListKeys is the maximum number of keys that can be requested in a single ListObjects call.
+// This is the maximum of a default server; a server configured with a lower maximum may return fewer keys.
+const MaxListKeys = 1000
+
 type ListObjectsCommand struct {
 	Bucket     string
 	StartAfter string
-	// MaxKeys limits the results to max keys. Defaults to 1000. Max is 1000.
+	// MaxKeys limits the results to max keys. Defaults to MaxListKeys if 0.
+	// Values outside of 0 to MaxListKeys are rejected with ErrInvalidArgument.
 	MaxKeys   int
 	Delimiter string
 	Prefix    string
+	// IncludeMetadata returns the ETag and user-defined metadata of every object,
+	// so that they do not have to be requested with HeadObject.
+	IncludeMetadata bool
 }
 
 type ListObjectsResult struct {
@@ -285,76 +951,362 @@ type ListObjectsResult struct {
 }
 
 func (c *Client) ListObjects(ctx context.Context, r ListObjectsCommand) (*ListObjectsResult, error) {
+	if r.MaxKeys < 0 || r.MaxKeys > MaxListKeys {
+		return nil, fmt.Errorf("%w: MaxKeys must be between 0 and %d, got %d", ErrInvalidArgument, MaxListKeys, r.MaxKeys)
+	}
 	maxKeys := r.MaxKeys
-	if maxKeys < 1 {
-		maxKeys = 1000
+	if maxKeys == 0 {
+		maxKeys = MaxListKeys
 	}
 	q := url.Values{}
 	q.Add("start-after", r.StartAfter)
 	q.Add("max-keys", strconv.Itoa(maxKeys))
 	q.Add("delimiter", r.Delimiter)
 	q.Add("prefix", r.Prefix)
+	if r.IncludeMetadata {
+		q.Add("include-metadata", "true")
+	}
 	q.Encode()
 	res, body, err := c.doReq(ctx, R{
+		op:    OperationListObjects,
 		path:  r.Bucket,
 		query: q,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if res.StatusCode == 404 {
+		return nil, ErrBucketNotFound
+	}
+	if res.StatusCode == 503 {
+		return nil, fmt.Errorf("unable to list objects: %w", ErrServiceUnavailable)
+	}
 	if res.StatusCode != 200 {
 		return nil, fmt.Errorf("unable to list objects: %d", res.StatusCode)
 	}
 	var listResult ListObjectsResult
-	if err := json.Unmarshal(body, &listResult); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal server response: %v", err)
+	if err := c.unmarshalResponse(res, body, &listResult); err != nil {
+		return nil, err
 	}
 	return &listResult, nil
 }
 
+// PrefixNonEmpty checks whether at least one object exists below prefix, requesting a single key.
+// If the bucket does not exist, the method returns ErrBucketNotFound.
+func (c *Client) PrefixNonEmpty(ctx context.Context, bucket, prefix string) (bool, error) {
+	page, err := c.ListObjects(ctx, ListObjectsCommand{
+		Bucket:  bucket,
+		Prefix:  prefix,
+		MaxKeys: 1,
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(page.Objects) > 0, nil
+}
+
+// ListCommonPrefixes lists the "directories" directly below the given prefix, using "/" as delimiter.
+// The prefix should either be empty or end with "/". The method pages through all results.
+func (c *Client) ListCommonPrefixes(ctx context.Context, bucket, prefix string) ([]string, error) {
+	prefixes := make([]string, 0)
+	err := c.walkObjects(ctx, ListObjectsCommand{
+		Bucket:    bucket,
+		Prefix:    prefix,
+		Delimiter: "/",
+	}, func(page *ListObjectsResult) error {
+		prefixes = append(prefixes, page.CommonPrefixes...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prefixes, nil
+}
+
+// walkObjects calls fn for every page of the listing.
+func (c *Client) walkObjects(ctx context.Context, cmd ListObjectsCommand, fn func(page *ListObjectsResult) error) error {
+	p := NewListObjectsPaginator(c, cmd)
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type ReadObjectResult struct {
 	ContentType   string
 	ContentLength int64
-	body          io.ReadCloser
+	// ContentRange is the range that was returned for a range read, nil otherwise.
+	ContentRange *ContentRange
+	// Metadata is the user-defined metadata of the object.
+	Metadata map[string]string
+	// ContentEncoding is the encoding of the returned content. It is empty if the content was decoded
+	// automatically, which is the default for gzip encoded objects.
+	ContentEncoding string
+	ETag            string
+	// VersionId is the version of the object in a versioned bucket.
+	VersionId string
+	// LastModified is the time the object was last modified. It is zero if the server did not send it.
+	LastModified time.Time
+	// Header holds all response headers. It is nil for objects read from the origin.
+	Header http.Header
+	ctx    context.Context
+	body   io.ReadCloser
 }
 
+// Read reads the content of the object. Once the context of the ReadObject call is done,
+// Read returns the context error.
 func (r *ReadObjectResult) Read(p []byte) (int, error) {
-	return r.body.Read(p)
+	n, err := r.body.Read(p)
+	if err != nil && err != io.EOF && r.ctx.Err() != nil {
+		return n, r.ctx.Err()
+	}
+	return n, err
 }
 
 func (r *ReadObjectResult) Close() error {
 	return r.body.Close()
 }
 
+type ReadObjectCommand struct {
+	Bucket string
+	Key    string
+	// Range reads only the given range of the object. If nil, the whole object is read.
+	Range *ByteRange
+	// IfMatch reads the object only if its ETag matches. Otherwise, ErrPreconditionFailed is returned.
+	IfMatch string
+	// IfNoneMatch reads the object only if its ETag does not match. Otherwise, ErrNotModified is returned.
+	IfNoneMatch string
+	// IfModifiedSince reads the object only if it has been modified after the given time.
+	// Otherwise, ErrNotModified is returned.
+	IfModifiedSince time.Time
+	// DisableAutoDecompress returns gzip encoded objects as stored instead of decoding them.
+	// The encoding is reported in ReadObjectResult.ContentEncoding.
+	DisableAutoDecompress bool
+	// VerifyChecksum verifies complete reads against the checksum or MD5 digest reported by the server.
+	// If the data does not match, reading the last bytes fails with ErrChecksumMismatch.
+	// Range reads, automatically decompressed reads and objects without a verifiable checksum are not verified.
+	VerifyChecksum bool
+	// VersionId reads a specific version of the object in a versioned bucket.
+	VersionId string
+	// MaxSize refuses to download objects larger than the given number of bytes with ErrObjectTooLarge.
+	// If the server does not report the size upfront, reading fails once more than MaxSize bytes were read.
+	// If 0, the size is not limited.
+	MaxSize int64
+}
+
 // ReadObject reads an object from STOR.
 // Clients are expected to read and close the returned ReadObjectResult.
 // If the object cannot be found, the method returns ErrObjectNotFound.
+// Use ReadObjectWithOptions for ranges, conditions and other read options.
 func (c *Client) ReadObject(ctx context.Context, bucket, key string) (*ReadObjectResult, error) {
-	req, err := c.createReq(ctx, R{
-		path: bucket + "/" + key,
-	})
+	return c.ReadObjectWithOptions(ctx, ReadObjectCommand{Bucket: bucket, Key: key})
+}
+
+// ReadObjectRange reads the given range of an object.
+// Clients are expected to read and close the returned ReadObjectResult.
+// If the range cannot be satisfied, the method returns ErrInvalidRange.
+func (c *Client) ReadObjectRange(ctx context.Context, bucket, key string, r ByteRange) (*ReadObjectResult, error) {
+	return c.ReadObjectWithOptions(ctx, ReadObjectCommand{Bucket: bucket, Key: key, Range: &r})
+}
+
+// ReadObjectWithOptions reads an object from STOR.
+// Clients are expected to read and close the returned ReadObjectResult.
+// If the object cannot be found, the method returns ErrObjectNotFound.
+// If a range is requested that cannot be satisfied, the method returns ErrInvalidRange.
+// Conditional reads return ErrNotModified or ErrPreconditionFailed if their condition is not met.
+// If the client was created with ClientOptions.SetOrigin, objects that cannot be found are read from the origin,
+// unless a range, a condition or a version is requested.
+func (c *Client) ReadObjectWithOptions(ctx context.Context, cmd ReadObjectCommand) (*ReadObjectResult, error) {
+	res, err := c.ReadObjectRaw(ctx, cmd)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := c.httpClient.Do(req)
+	if res.StatusCode == 404 {
+		res.Body.Close()
+		if c.origin != nil && cmd.fromOrigin() {
+			return c.readFromOrigin(ctx, cmd)
+		}
+		return nil, ErrObjectNotFound
+	}
+
+	if res.StatusCode == 304 {
+		res.Body.Close()
+		return nil, ErrNotModified
+	}
+
+	if res.StatusCode == 412 {
+		res.Body.Close()
+		return nil, ErrPreconditionFailed
+	}
+
+	if res.StatusCode == 416 {
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRange, res.Header.Get("Content-Range"))
+	}
+
+	if res.StatusCode != 200 && res.StatusCode != 206 {
+		res.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %v", res.StatusCode)
+	}
+
+	result := &ReadObjectResult{
+		ContentType:     res.Header.Get("Content-Type"),
+		ContentLength:   res.ContentLength,
+		Metadata:        metadataFromHeader(res.Header),
+		ContentEncoding: res.Header.Get("Content-Encoding"),
+		ETag:            res.Header.Get("ETag"),
+		VersionId:       res.Header.Get(versionIdHeader),
+		Header:          res.Header,
+		ctx:             ctx,
+		body:            res.Body,
+	}
+	if lastModified := res.Header.Get("Last-Modified"); lastModified != "" {
+		if t, err := http.ParseTime(lastModified); err == nil {
+			result.LastModified = t
+		}
+	}
+	if res.StatusCode == 206 {
+		contentRange, err := parseContentRange(res.Header.Get("Content-Range"))
+		if err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+		result.ContentRange = contentRange
+	}
+	if cmd.MaxSize > 0 {
+		if res.ContentLength > cmd.MaxSize {
+			res.Body.Close()
+			return nil, fmt.Errorf("%w: %d bytes exceeds the maximum of %d", ErrObjectTooLarge, res.ContentLength, cmd.MaxSize)
+		}
+		result.body = &maxSizeReader{ReadCloser: res.Body, remaining: cmd.MaxSize}
+	}
+	if cmd.VerifyChecksum && res.StatusCode == 200 && !res.Uncompressed {
+		if expected := expectedChecksumFromHeader(res.Header); expected != nil {
+			result.body = newVerifyingReader(result.body, expected)
+		}
+	}
+	return result, nil
+}
+
+// ReadObjectRaw reads an object from STOR and returns the server response as is, regardless of its status code.
+// This is useful to forward responses verbatim, e.g. from a reverse proxy.
+// Clients are expected to read and close the response body.
+func (c *Client) ReadObjectRaw(ctx context.Context, cmd ReadObjectCommand) (*http.Response, error) {
+	header := http.Header{}
+	if cmd.Range != nil {
+		if err := cmd.Range.validate(); err != nil {
+			return nil, err
+		}
+		header.Set("Range", cmd.Range.header())
+	}
+	if cmd.DisableAutoDecompress {
+		// net/http only decodes responses transparently if it requested the encoding itself
+		header.Set("Accept-Encoding", "gzip")
+	}
+	if cmd.IfMatch != "" {
+		header.Set("If-Match", cmd.IfMatch)
+	}
+	if cmd.IfNoneMatch != "" {
+		header.Set("If-None-Match", cmd.IfNoneMatch)
+	}
+	if !cmd.IfModifiedSince.IsZero() {
+		header.Set("If-Modified-Since", cmd.IfModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	query, err := c.versionQuery(ctx, cmd.VersionId)
 	if err != nil {
 		return nil, err
 	}
+	return c.streamReq(ctx, R{
+		op:     OperationReadObject,
+		path:   objectPath(cmd.Bucket, cmd.Key),
+		query:  query,
+		header: header,
+	})
+}
+
+type HeadObjectCommand struct {
+	Bucket string
+	Key    string
+	// VersionId returns the metadata of a specific version of the object in a versioned bucket.
+	VersionId string
+}
+
+type HeadObjectResult struct {
+	ContentType string
+	Size        int64
+	ETag        string
+	CreatedAt   time.Time
+	// Metadata is the user-defined metadata of the object.
+	Metadata map[string]string
+	// VersionId is the version of the object in a versioned bucket.
+	VersionId string
+	// StorageClass is the storage tier of the object. It is empty if the server does not support storage classes.
+	StorageClass StorageClass
+	// Generation is the generation of the object, 0 if the server does not report generations.
+	Generation int64
+}
 
+// HeadObject returns the metadata of an object without reading its content.
+// If the object cannot be found, the method returns ErrObjectNotFound.
+func (c *Client) HeadObject(ctx context.Context, cmd HeadObjectCommand) (*HeadObjectResult, error) {
+	query, err := c.versionQuery(ctx, cmd.VersionId)
+	if err != nil {
+		return nil, err
+	}
+	res, _, err := c.doReq(ctx, R{
+		op:     OperationHeadObject,
+		method: "HEAD",
+		path:   objectPath(cmd.Bucket, cmd.Key),
+		query:  query,
+	})
+	if err != nil {
+		return nil, err
+	}
 	if res.StatusCode == 404 {
 		return nil, ErrObjectNotFound
 	}
-
 	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code: %v", res.StatusCode)
+		return nil, fmt.Errorf("unable to head object: %v", res.StatusCode)
 	}
 
-	return &ReadObjectResult{
-		ContentType:   res.Header.Get("Content-Type"),
-		ContentLength: res.ContentLength,
-		body:          res.Body,
-	}, nil
+	result := &HeadObjectResult{
+		ContentType:  res.Header.Get("Content-Type"),
+		Size:         res.ContentLength,
+		ETag:         res.Header.Get("ETag"),
+		Metadata:     metadataFromHeader(res.Header),
+		VersionId:    res.Header.Get(versionIdHeader),
+		StorageClass: StorageClass(res.Header.Get(storageClassHeader)),
+		Generation:   generationFromHeader(res.Header),
+	}
+	if lastModified := res.Header.Get("Last-Modified"); lastModified != "" {
+		if t, err := http.ParseTime(lastModified); err == nil {
+			result.CreatedAt = t
+		}
+	}
+	return result, nil
+}
+
+// ObjectExists checks whether an object exists without reading its content.
+// Errors other than a missing object, e.g. transport errors, are returned as errors.
+func (c *Client) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	_, err := c.HeadObject(ctx, HeadObjectCommand{
+		Bucket: bucket,
+		Key:    key,
+	})
+	if errors.Is(err, ErrObjectNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 type DeleteObjectsCommand struct {
@@ -366,9 +1318,10 @@ type DeleteObjectsResult struct {
 }
 
 type DeleteResult struct {
-	Key     string `json:"key"`
-	Deleted bool   `json:"deleted"`
-	Error   *Error `json:"error,omitempty"`
+	Key       string `json:"key"`
+	VersionId string `json:"versionId,omitempty"`
+	Deleted   bool   `json:"deleted"`
+	Error     *Error `json:"error,omitempty"`
 }
 
 type deleteObjectsRequest struct {
@@ -376,6 +1329,24 @@ type deleteObjectsRequest struct {
 }
 
 func (c *Client) DeleteObjects(ctx context.Context, cmd DeleteObjectsCommand) (*DeleteObjectsResult, error) {
+	if err := c.limits.validateBatchDelete(len(cmd.Objects)); err != nil {
+		return nil, err
+	}
+	for _, o := range cmd.Objects {
+		if err := c.tenantPolicy.checkKey(o.Key); err != nil {
+			return nil, err
+		}
+		if o.VersionId != "" {
+			if err := c.requireFeature(ctx, FeatureVersioning); err != nil {
+				return nil, err
+			}
+		}
+		if o.IfGenerationMatch != 0 || o.IfGenerationNotMatch != 0 {
+			if err := c.requireFeature(ctx, FeatureGenerations); err != nil {
+				return nil, err
+			}
+		}
+	}
 	data, err := json.Marshal(deleteObjectsRequest{Objects: cmd.Objects})
 	if err != nil {
 		return nil, err
@@ -383,6 +1354,7 @@ func (c *Client) DeleteObjects(ctx context.Context, cmd DeleteObjectsCommand) (*
 	query := url.Values{}
 	query.Set("delete", "")
 	res, body, err := c.doReq(ctx, R{
+		op:          OperationDeleteObjects,
 		method:      "POST",
 		path:        cmd.Bucket,
 		query:       query,
@@ -397,7 +1369,7 @@ func (c *Client) DeleteObjects(ctx context.Context, cmd DeleteObjectsCommand) (*
 	}
 
 	var result DeleteObjectsResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
 		return nil, err
 	}
 
@@ -407,3 +1379,15 @@ func (c *Client) DeleteObjects(ctx context.Context, cmd DeleteObjectsCommand) (*
 func objectPath(bucketName, key string) string {
 	return bucketName + "/" + key
 }
+
+// setExpiresHeader sets the expiration of an object, if one is given. Expirations in the past are rejected.
+func setExpiresHeader(header http.Header, expires time.Time) error {
+	if expires.IsZero() {
+		return nil
+	}
+	if !expires.After(time.Now()) {
+		return fmt.Errorf("%w: expiration %v is in the past", ErrInvalidArgument, expires)
+	}
+	header.Set("Stor-Expires", expires.UTC().Format(http.TimeFormat))
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+type ObjectCacheOptions struct {
+	// TTL is the time a read object is served from the cache without revalidation.
+	TTL time.Duration
+	// MaxStale is the time after the TTL during which a stale object is still served immediately while it is
+	// revalidated in the background. Once it has passed, Get blocks until the object has been revalidated.
+	MaxStale time.Duration
+	// MaxObjectSize is the maximum size of a cached object. Get returns ErrObjectTooLarge for larger objects.
+	// Defaults to DefaultMaxCachedObjectSize if 0.
+	MaxObjectSize int64
+	// MaxEntries is the maximum number of cached objects. Once it is reached, the object that was fetched the
+	// longest time ago is evicted. Defaults to DefaultMaxCachedObjects if 0.
+	MaxEntries int
+}
+
+const (
+	// DefaultMaxCachedObjectSize is the default maximum size of an object in an ObjectCache.
+	DefaultMaxCachedObjectSize = 1 << 20
+	// DefaultMaxCachedObjects is the default maximum number of objects in an ObjectCache.
+	DefaultMaxCachedObjects = 1000
+)
+
+// ObjectCache serves the content of small objects from memory, e.g. configuration files stored in a bucket.
+// Stale objects are served immediately while they are refreshed in the background with a conditional read.
+// Concurrent reads of the same object that miss the cache share a single request.
+type ObjectCache struct {
+	client   *Client
+	opts     ObjectCacheOptions
+	mu       sync.Mutex
+	entries  map[objectCacheKey]*objectCacheEntry
+	inflight map[objectCacheKey]*objectCacheCall
+}
+
+type objectCacheKey struct {
+	bucket string
+	key    string
+}
+
+type objectCacheEntry struct {
+	data         []byte
+	etag         string
+	fetchedAt    time.Time
+	revalidating bool
+}
+
+// objectCacheCall is a fetch in progress that concurrent reads of the same object wait for.
+type objectCacheCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// NewObjectCache creates an empty object cache.
+func (c *Client) NewObjectCache(opts ObjectCacheOptions) *ObjectCache {
+	if opts.MaxObjectSize <= 0 {
+		opts.MaxObjectSize = DefaultMaxCachedObjectSize
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxCachedObjects
+	}
+	return &ObjectCache{
+		client:   c,
+		opts:     opts,
+		entries:  make(map[objectCacheKey]*objectCacheEntry),
+		inflight: make(map[objectCacheKey]*objectCacheCall),
+	}
+}
+
+// Get returns the content of the object at key. The returned slice must not be modified.
+func (oc *ObjectCache) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	k := objectCacheKey{bucket: bucket, key: key}
+	oc.mu.Lock()
+	e, ok := oc.entries[k]
+	if ok {
+		age := time.Since(e.fetchedAt)
+		if age <= oc.opts.TTL {
+			oc.mu.Unlock()
+			return e.data, nil
+		}
+		if age <= oc.opts.TTL+oc.opts.MaxStale {
+			if !e.revalidating {
+				e.revalidating = true
+				go oc.revalidate(k, e.etag)
+			}
+			oc.mu.Unlock()
+			return e.data, nil
+		}
+	}
+	oc.mu.Unlock()
+
+	etag := ""
+	if ok {
+		etag = e.etag
+	}
+	return oc.load(ctx, k, etag)
+}
+
+// Invalidate removes the object at key from the cache.
+func (oc *ObjectCache) Invalidate(bucket, key string) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	delete(oc.entries, objectCacheKey{bucket: bucket, key: key})
+}
+
+func (oc *ObjectCache) revalidate(k objectCacheKey, etag string) {
+	if _, err := oc.load(context.Background(), k, etag); err != nil {
+		oc.client.logger.Printf("stor: unable to revalidate cached object bucket=%s key=%s: %v", k.bucket, k.key, err)
+		oc.mu.Lock()
+		if e, ok := oc.entries[k]; ok {
+			e.revalidating = false
+		}
+		oc.mu.Unlock()
+	}
+}
+
+// load fetches the object, or waits for the fetch that is already in progress for the same object.
+func (oc *ObjectCache) load(ctx context.Context, k objectCacheKey, etag string) ([]byte, error) {
+	oc.mu.Lock()
+	call, ok := oc.inflight[k]
+	if !ok {
+		call = &objectCacheCall{done: make(chan struct{})}
+		oc.inflight[k] = call
+	}
+	oc.mu.Unlock()
+
+	if ok {
+		select {
+		case <-call.done:
+			return call.data, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call.data, call.err = oc.fetch(ctx, k, etag)
+	oc.mu.Lock()
+	delete(oc.inflight, k)
+	oc.mu.Unlock()
+	close(call.done)
+	return call.data, call.err
+}
+
+// fetch reads the object, conditional on etag if it is not empty, and updates the cache.
+func (oc *ObjectCache) fetch(ctx context.Context, k objectCacheKey, etag string) (data []byte, err error) {
+	defer recoverPanic(oc.client.logger, &err)
+	res, err := oc.client.ReadObjectWithOptions(ctx, ReadObjectCommand{
+		Bucket:      k.bucket,
+		Key:         k.key,
+		IfNoneMatch: etag,
+		MaxSize:     oc.opts.MaxObjectSize,
+	})
+	if errors.Is(err, ErrNotModified) {
+		oc.mu.Lock()
+		e, ok := oc.entries[k]
+		if ok {
+			e.fetchedAt = time.Now()
+			e.revalidating = false
+		}
+		oc.mu.Unlock()
+		if !ok {
+			// the entry was invalidated in the meantime
+			return oc.fetch(ctx, k, "")
+		}
+		return e.data, nil
+	}
+	if errors.Is(err, ErrObjectNotFound) {
+		oc.Invalidate(k.bucket, k.key)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	data, err = io.ReadAll(res)
+	if err != nil {
+		return nil, err
+	}
+
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if _, ok := oc.entries[k]; !ok && len(oc.entries) >= oc.opts.MaxEntries {
+		oc.evictOldest()
+	}
+	oc.entries[k] = &objectCacheEntry{
+		data:      data,
+		etag:      res.ETag,
+		fetchedAt: time.Now(),
+	}
+	return data, nil
+}
+
+// evictOldest removes the entry that was fetched the longest time ago. oc.mu must be held.
+func (oc *ObjectCache) evictOldest() {
+	var oldest objectCacheKey
+	var oldestAt time.Time
+	for k, e := range oc.entries {
+		if oldestAt.IsZero() || e.fetchedAt.Before(oldestAt) {
+			oldest, oldestAt = k, e.fetchedAt
+		}
+	}
+	delete(oc.entries, oldest)
+}
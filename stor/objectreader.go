@@ -0,0 +1,150 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ObjectReader is an io.ReadSeekCloser and io.ReaderAt over an object, backed by range requests.
+// Seeking is free; the next Read starts a new range request at the current offset.
+//
+// All reads are conditional on the ETag the object had when the reader was created. If the object is
+// modified while it is being read, reads fail with ErrPreconditionFailed.
+type ObjectReader struct {
+	client *Client
+	ctx    context.Context
+	bucket string
+	key    string
+	size   int64
+	etag   string
+	offset int64
+	body   io.ReadCloser
+}
+
+// NewObjectReader creates a seekable reader for the object at key.
+// If the object cannot be found, the method returns ErrObjectNotFound.
+func (c *Client) NewObjectReader(ctx context.Context, bucket, key string) (*ObjectReader, error) {
+	head, err := c.HeadObject(ctx, HeadObjectCommand{
+		Bucket: bucket,
+		Key:    key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectReader{
+		client: c,
+		ctx:    ctx,
+		bucket: bucket,
+		key:    key,
+		size:   head.Size,
+		etag:   head.ETag,
+	}, nil
+}
+
+// Size returns the size of the object.
+func (r *ObjectReader) Size() int64 {
+	return r.size
+}
+
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, err := r.open(ByteRange{Start: r.offset, End: -1})
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if err == io.EOF && r.offset < r.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.offset + offset
+	case io.SeekEnd:
+		abs = r.size + offset
+	default:
+		return 0, fmt.Errorf("%w: invalid whence %d", ErrInvalidArgument, whence)
+	}
+	if abs < 0 {
+		return 0, fmt.Errorf("%w: negative position %d", ErrInvalidArgument, abs)
+	}
+	if abs != r.offset {
+		r.closeBody()
+		r.offset = abs
+	}
+	return abs, nil
+}
+
+// ReadAt reads len(p) bytes at off with a separate range request. It does not change the offset of the reader
+// and can be called concurrently.
+func (r *ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%w: negative offset %d", ErrInvalidArgument, off)
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	end := off + int64(len(p)) - 1
+	if end >= r.size {
+		end = r.size - 1
+	}
+	body, err := r.open(ByteRange{Start: off, End: end})
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	n, err := io.ReadFull(body, p[:end-off+1])
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return n, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close closes the current range request.
+func (r *ObjectReader) Close() error {
+	r.closeBody()
+	return nil
+}
+
+func (r *ObjectReader) open(byteRange ByteRange) (io.ReadCloser, error) {
+	return r.client.ReadObjectWithOptions(r.ctx, ReadObjectCommand{
+		Bucket:  r.bucket,
+		Key:     r.key,
+		Range:   &byteRange,
+		IfMatch: r.etag,
+	})
+}
+
+func (r *ObjectReader) closeBody() {
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+// OperationName identifies an API operation of the client, e.g. for per-operation timeouts.
+type OperationName string
+
+const (
+	OperationCreateObject            OperationName = "CreateObject"
+	OperationAppendObject            OperationName = "AppendObject"
+	OperationCopyObject              OperationName = "CopyObject"
+	OperationReadObject              OperationName = "ReadObject"
+	OperationHeadObject              OperationName = "HeadObject"
+	OperationGetObjectAttributes     OperationName = "GetObjectAttributes"
+	OperationQueryObject             OperationName = "QueryObject"
+	OperationPutObjectLegalHold      OperationName = "PutObjectLegalHold"
+	OperationGetObjectLegalHold      OperationName = "GetObjectLegalHold"
+	OperationDeleteObjects           OperationName = "DeleteObjects"
+	OperationListObjects             OperationName = "ListObjects"
+	OperationListObjectVersions      OperationName = "ListObjectVersions"
+	OperationListChanges             OperationName = "ListChanges"
+	OperationCreateMultipartUpload   OperationName = "CreateMultipartUpload"
+	OperationUploadPart              OperationName = "UploadPart"
+	OperationUploadPartCopy          OperationName = "UploadPartCopy"
+	OperationCompleteMultipartUpload OperationName = "CompleteMultipartUpload"
+	OperationAbortMultipartUpload    OperationName = "AbortMultipartUpload"
+	OperationListParts               OperationName = "ListParts"
+	OperationListMultipartUploads    OperationName = "ListMultipartUploads"
+	OperationListBuckets             OperationName = "ListBuckets"
+	OperationCreateBucket            OperationName = "CreateBucket"
+	OperationDeleteBucket            OperationName = "DeleteBucket"
+	OperationCreateArchive           OperationName = "CreateArchive"
+	OperationAddArchiveEntries       OperationName = "AddArchiveEntries"
+	OperationCompleteArchive         OperationName = "CompleteArchive"
+	OperationAbortArchive            OperationName = "AbortArchive"
+	OperationGetArchive              OperationName = "GetArchive"
+	OperationCreateNonce             OperationName = "CreateNonce"
+	OperationCreateScopedToken       OperationName = "CreateScopedToken"
+	OperationGetBucketNoncePolicy    OperationName = "GetBucketNoncePolicy"
+	OperationPutBucketNoncePolicy    OperationName = "PutBucketNoncePolicy"
+)
+
+// Operation describes an API operation of the client.
+type Operation struct {
+	Name   OperationName
+	Method string
+	// PathTemplate is the path of the request with the placeholders {bucket} and {key},
+	// followed by the query parameters that identify the operation.
+	PathTemplate string
+	// Idempotent reports whether repeating a successful request leaves the server in the same state.
+	Idempotent bool
+}
+
+var operations = []Operation{
+	{OperationCreateObject, "PUT", "/{bucket}/{key}", true},
+	{OperationAppendObject, "POST", "/{bucket}/{key}?append&offset", false},
+	{OperationCopyObject, "PUT", "/{bucket}/{key}", true},
+	{OperationReadObject, "GET", "/{bucket}/{key}", true},
+	{OperationHeadObject, "HEAD", "/{bucket}/{key}", true},
+	{OperationGetObjectAttributes, "GET", "/{bucket}/{key}?attributes", true},
+	{OperationQueryObject, "POST", "/{bucket}/{key}?query", true},
+	{OperationPutObjectLegalHold, "PUT", "/{bucket}/{key}?legal-hold", true},
+	{OperationGetObjectLegalHold, "GET", "/{bucket}/{key}?legal-hold", true},
+	{OperationDeleteObjects, "POST", "/{bucket}?delete", true},
+	{OperationListObjects, "GET", "/{bucket}", true},
+	{OperationListObjectVersions, "GET", "/{bucket}?versions", true},
+	{OperationListChanges, "GET", "/{bucket}?changes", true},
+	{OperationCreateMultipartUpload, "POST", "/{bucket}/{key}?uploads", false},
+	{OperationUploadPart, "PUT", "/{bucket}/{key}?upload-id&part-number", true},
+	{OperationUploadPartCopy, "PUT", "/{bucket}/{key}?upload-id&part-number", true},
+	{OperationCompleteMultipartUpload, "POST", "/{bucket}/{key}?upload-id", false},
+	{OperationAbortMultipartUpload, "DELETE", "/{bucket}/{key}?upload-id", true},
+	{OperationListParts, "GET", "/{bucket}/{key}?upload-id", true},
+	{OperationListMultipartUploads, "GET", "/{bucket}?uploads", true},
+	{OperationListBuckets, "GET", "/", true},
+	{OperationCreateBucket, "PUT", "/{bucket}", true},
+	{OperationDeleteBucket, "DELETE", "/{bucket}", true},
+	{OperationCreateArchive, "POST", "/{bucket}/{key}?archives&type", false},
+	{OperationAddArchiveEntries, "PUT", "/{bucket}/{key}?archive-id", false},
+	{OperationCompleteArchive, "POST", "/{bucket}/{key}?archive-id", false},
+	{OperationAbortArchive, "DELETE", "/{bucket}/{key}?archive-id", true},
+	{OperationGetArchive, "GET", "/{bucket}/{key}?archive-id", true},
+	{OperationCreateNonce, "POST", "/{bucket}/{key}?nonces&ttl", false},
+	{OperationCreateScopedToken, "POST", "/?tokens", false},
+	{OperationGetBucketNoncePolicy, "GET", "/{bucket}?nonce-policy", true},
+	{OperationPutBucketNoncePolicy, "PUT", "/{bucket}?nonce-policy", true},
+}
+
+// Operations returns a description of all operations of the client, e.g. to build allowlists or quotas
+// in a gateway in front of the server.
+func Operations() []Operation {
+	result := make([]Operation, len(operations))
+	copy(result, operations)
+	return result
+}
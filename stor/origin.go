@@ -0,0 +1,88 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+)
+
+// OriginObject is an object fetched from an origin.
+type OriginObject struct {
+	ContentType string
+	Data        io.ReadCloser
+}
+
+// OriginFunc fetches an object that could not be found in STOR from an origin, e.g. an external URL.
+// It returns ErrObjectNotFound if the origin does not have the object either.
+type OriginFunc func(ctx context.Context, bucket, key string) (*OriginObject, error)
+
+// maxBackfillSize is the size up to which objects read from the origin are buffered to be backfilled.
+// Larger objects are returned without being backfilled.
+const maxBackfillSize = 64 << 20
+
+// fromOrigin reports whether an object that cannot be found may be read from the origin. Ranges, conditions and
+// versions refer to the object in STOR and cannot be applied to the origin.
+func (cmd ReadObjectCommand) fromOrigin() bool {
+	return cmd.Range == nil && cmd.VersionId == "" && cmd.IfMatch == "" && cmd.IfNoneMatch == "" &&
+		cmd.IfModifiedSince.IsZero()
+}
+
+// readFromOrigin reads an object from the origin and stores it in the bucket if backfill is enabled.
+func (c *Client) readFromOrigin(ctx context.Context, cmd ReadObjectCommand) (*ReadObjectResult, error) {
+	obj, err := c.origin(ctx, cmd.Bucket, cmd.Key)
+	if err != nil {
+		return nil, err
+	}
+	body := obj.Data
+	if cmd.MaxSize > 0 {
+		body = &maxSizeReader{ReadCloser: obj.Data, remaining: cmd.MaxSize}
+	}
+	if !c.originBackfill {
+		return &ReadObjectResult{
+			ContentType:   obj.ContentType,
+			ContentLength: -1,
+			ctx:           ctx,
+			body:          body,
+		}, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBackfillSize+1))
+	if err != nil {
+		obj.Data.Close()
+		return nil, err
+	}
+	if len(data) > maxBackfillSize {
+		c.logger.Printf("stor: object too large to backfill bucket=%s key=%s", cmd.Bucket, cmd.Key)
+		return &ReadObjectResult{
+			ContentType:   obj.ContentType,
+			ContentLength: -1,
+			ctx:           ctx,
+			body: struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(data), body), obj.Data},
+		}, nil
+	}
+	obj.Data.Close()
+	// the object may have been written since it was found missing, which must not be overwritten
+	if _, err := c.CreateObject(ctx, CreateObjectCommand{
+		Bucket:      cmd.Bucket,
+		Key:         cmd.Key,
+		ContentType: obj.ContentType,
+		Data:        bytes.NewReader(data),
+		IfNoneMatch: true,
+	}); err != nil && !errors.Is(err, ErrPreconditionFailed) {
+		c.logger.Printf("stor: unable to backfill object bucket=%s key=%s: %v", cmd.Bucket, cmd.Key, err)
+	}
+	return &ReadObjectResult{
+		ContentType:   obj.ContentType,
+		ContentLength: int64(len(data)),
+		ctx:           ctx,
+		body:          io.NopCloser(bytes.NewReader(data)),
+	}, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListObjectsPaginator pages through the results of ListObjects, threading StartAfter between pages.
+// If the server is temporarily unavailable, a page is retried with exponential backoff.
+type ListObjectsPaginator struct {
+	client *Client
+	cmd    ListObjectsCommand
+	done   bool
+}
+
+// NewListObjectsPaginator creates a paginator that starts at cmd.StartAfter.
+func NewListObjectsPaginator(client *Client, cmd ListObjectsCommand) *ListObjectsPaginator {
+	return &ListObjectsPaginator{
+		client: client,
+		cmd:    cmd,
+	}
+}
+
+// HasMorePages returns true if there are more pages to fetch.
+func (p *ListObjectsPaginator) HasMorePages() bool {
+	return !p.done
+}
+
+// NextPage fetches the next page. If the page cannot be fetched, it can be retried by calling NextPage again.
+func (p *ListObjectsPaginator) NextPage(ctx context.Context) (*ListObjectsResult, error) {
+	if !p.HasMorePages() {
+		return nil, fmt.Errorf("%w: no more pages", ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var page *ListObjectsResult
+	err := p.client.retry(ctx, OperationListObjects, p.cmd.Bucket, p.client.listRetries, func() error {
+		var err error
+		page, err = p.client.ListObjects(ctx, p.cmd)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	next := nextStartAfter(page)
+	if !page.IsTruncated || next == "" {
+		p.done = true
+	} else {
+		p.cmd.StartAfter = next
+	}
+	return page, nil
+}
+
+// nextStartAfter returns the last key or common prefix of the page, whichever sorts last.
+func nextStartAfter(page *ListObjectsResult) string {
+	next := ""
+	if len(page.Objects) > 0 {
+		next = page.Objects[len(page.Objects)-1].Key
+	}
+	if len(page.CommonPrefixes) > 0 {
+		if p := page.CommonPrefixes[len(page.CommonPrefixes)-1]; p > next {
+			next = p
+		}
+	}
+	return next
+}
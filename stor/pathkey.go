@@ -0,0 +1,189 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy defines how PathMapper handles symbolic links.
+type SymlinkPolicy int
+
+const (
+	// SymlinkSkip ignores symbolic links. This is the default.
+	SymlinkSkip SymlinkPolicy = iota
+	// SymlinkFollow maps the target of symbolic links. Links to directories are walked recursively.
+	SymlinkFollow
+	// SymlinkError fails on symbolic links.
+	SymlinkError
+)
+
+// PathMapper maps local file paths below Root to object keys below Prefix and back.
+// Keys always use "/" as separator, independent of the operating system.
+type PathMapper struct {
+	// Root is the local directory that is mapped.
+	Root string
+	// Prefix is prepended to all keys.
+	Prefix Key
+	// LowerCase maps paths to lower case keys, which avoids duplicate keys from case-insensitive file systems.
+	LowerCase bool
+	// Symlinks defines how symbolic links are handled by Walk.
+	Symlinks SymlinkPolicy
+	// Exclude contains patterns in path.Match syntax. A path is excluded if a pattern matches its slash separated
+	// path relative to Root or its base name. Excluded directories are not walked.
+	Exclude []string
+}
+
+// KeyForPath returns the key of the file at p, which must be below Root.
+func (m PathMapper) KeyForPath(p string) (Key, error) {
+	rel, err := m.rel(p)
+	if err != nil {
+		return "", err
+	}
+	if m.LowerCase {
+		rel = strings.ToLower(rel)
+	}
+	return NewKey(string(JoinKey(m.Prefix, rel)))
+}
+
+// PathForKey returns the local path of key. Keys outside of Prefix, keys that would escape Root and keys that
+// contain a backslash are rejected.
+func (m PathMapper) PathForKey(key Key) (string, error) {
+	prefix := m.Prefix
+	if prefix != "" && !strings.HasSuffix(string(prefix), "/") {
+		prefix += "/"
+	}
+	if !key.HasPrefix(prefix) {
+		return "", fmt.Errorf("%w: %q is not below %q", ErrInvalidKey, key, prefix)
+	}
+	rel := string(key.TrimPrefix(prefix))
+	if strings.ContainsRune(rel, '\\') {
+		// a backslash is a separator on Windows, so the key would map to different paths on different systems
+		return "", fmt.Errorf("%w: %q contains a backslash", ErrInvalidKey, key)
+	}
+	for _, elem := range strings.Split(rel, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("%w: %q escapes the root directory", ErrInvalidKey, key)
+		}
+	}
+	rel = path.Clean("/" + rel)[1:]
+	if rel == "" {
+		return "", fmt.Errorf("%w: %q does not name a file", ErrInvalidKey, key)
+	}
+	p := filepath.Join(m.Root, filepath.FromSlash(rel))
+	if _, err := m.rel(p); err != nil {
+		return "", fmt.Errorf("%w: %q escapes the root directory", ErrInvalidKey, key)
+	}
+	return p, nil
+}
+
+// Excluded reports whether the file at p matches one of the Exclude patterns.
+func (m PathMapper) Excluded(p string) bool {
+	rel, err := m.rel(p)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range m.Exclude {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkFunc is called by PathMapper.Walk for every regular file.
+type WalkFunc func(p string, key Key, info fs.FileInfo) error
+
+// Walk calls fn for every regular file below Root that is not excluded, in lexical order.
+func (m PathMapper) Walk(fn WalkFunc) error {
+	return m.walk(m.Root, m.Root, fn, map[string]bool{})
+}
+
+// walk walks dir, which is mapped to the path below Root given by mapped.
+func (m PathMapper) walk(dir, mapped string, fn WalkFunc, visited map[string]bool) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if visited[realDir] {
+		return nil
+	}
+	visited[realDir] = true
+	// WalkDir does not descend into a symbolic link to a directory, so the resolved directory is walked
+	dir = realDir
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		mappedPath := filepath.Join(mapped, rel)
+		if p != dir && m.Excluded(mappedPath) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			switch m.Symlinks {
+			case SymlinkError:
+				return fmt.Errorf("%w: %s is a symbolic link", ErrInvalidArgument, p)
+			case SymlinkFollow:
+				info, err := os.Stat(p)
+				if err != nil {
+					return err
+				}
+				if info.IsDir() {
+					return m.walk(p, mappedPath, fn, visited)
+				}
+				if !info.Mode().IsRegular() {
+					return nil
+				}
+				return m.visit(mappedPath, p, info, fn)
+			default:
+				return nil
+			}
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return m.visit(mappedPath, p, info, fn)
+	})
+}
+
+func (m PathMapper) visit(mappedPath, p string, info fs.FileInfo, fn WalkFunc) error {
+	key, err := m.KeyForPath(mappedPath)
+	if err != nil {
+		return err
+	}
+	return fn(p, key, info)
+}
+
+// rel returns the slash separated path of p relative to Root.
+func (m PathMapper) rel(p string) (string, error) {
+	rel, err := filepath.Rel(m.Root, p)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%w: %s is not below %s", ErrInvalidArgument, p, m.Root)
+	}
+	return rel, nil
+}
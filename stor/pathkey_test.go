@@ -0,0 +1,42 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathForKey(t *testing.T) {
+	root := filepath.Join("data", "root")
+	m := PathMapper{Root: root, Prefix: "files"}
+	tests := []struct {
+		key     Key
+		want    string
+		wantErr bool
+	}{
+		{key: "files/a/b.txt", want: filepath.Join(root, "a", "b.txt")},
+		{key: "files/a//./b.txt", want: filepath.Join(root, "a", "b.txt")},
+		{key: "files/../x", wantErr: true},
+		{key: "files/a/../../x", wantErr: true},
+		{key: `files/a\..\..\x`, wantErr: true},
+		{key: `files/a\b.txt`, wantErr: true},
+		{key: "other/a.txt", wantErr: true},
+		{key: "files/", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := m.PathForKey(tt.key)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("PathForKey(%q) = %q, %v, want ErrInvalidKey", tt.key, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("PathForKey(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+)
+
+type PromoteObjectCommand struct {
+	// The bucket to promote the object to
+	Bucket string
+	// The bucket of the object to promote. Defaults to Bucket.
+	SourceBucket string
+	// The key of the object to promote, e.g. a build artifact
+	SourceKey string
+	// The release key. Promotion fails with ErrPreconditionFailed if it already exists.
+	DestKey string
+	// Immutable places a legal hold on the promoted object, so that it cannot be deleted or overwritten.
+	Immutable bool
+}
+
+type PromoteObjectResult struct {
+	ETag      string
+	Size      int64
+	VersionId string
+	// Immutable reports whether a legal hold was placed on the promoted object and verified.
+	Immutable bool
+}
+
+// PromoteObject copies an artifact to a release key that must not exist yet and verifies that the copy matches
+// the source. The copy is pinned to the ETag of the source, so a source that changes during the promotion fails
+// with ErrPreconditionFailed. A copy that does not match the source is deleted again and ErrChecksumMismatch is
+// returned. With Immutable, the promoted object is locked with a legal hold.
+func (c *Client) PromoteObject(ctx context.Context, cmd PromoteObjectCommand) (*PromoteObjectResult, error) {
+	sourceBucket := cmd.SourceBucket
+	if sourceBucket == "" {
+		sourceBucket = cmd.Bucket
+	}
+	if cmd.Immutable {
+		if err := c.requireFeature(ctx, FeatureLegalHold); err != nil {
+			return nil, err
+		}
+	}
+	source, err := c.HeadObject(ctx, HeadObjectCommand{Bucket: sourceBucket, Key: cmd.SourceKey})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read source: %w", err)
+	}
+	copied, err := c.CopyObject(ctx, CopyObjectCommand{
+		Bucket:        cmd.Bucket,
+		SourceBucket:  sourceBucket,
+		SourceKey:     cmd.SourceKey,
+		SourceIfMatch: source.ETag,
+		DestKey:       cmd.DestKey,
+		IfNoneMatch:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dest, err := c.HeadObject(ctx, HeadObjectCommand{Bucket: cmd.Bucket, Key: cmd.DestKey, VersionId: copied.VersionId})
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify promoted object: %w", err)
+	}
+	if dest.Size != source.Size || normalizeETag(dest.ETag) != normalizeETag(source.ETag) {
+		if deleteErr := c.deleteObject(ctx, cmd.Bucket, cmd.DestKey); deleteErr != nil {
+			c.logger.Printf("stor: unable to delete mismatching promoted object bucket=%s key=%s: %v", cmd.Bucket, cmd.DestKey, deleteErr)
+		}
+		return nil, fmt.Errorf("%w: promoted object has size %d and ETag %s, source has size %d and ETag %s",
+			ErrChecksumMismatch, dest.Size, dest.ETag, source.Size, source.ETag)
+	}
+
+	result := &PromoteObjectResult{ETag: dest.ETag, Size: dest.Size, VersionId: dest.VersionId}
+	if !cmd.Immutable {
+		return result, nil
+	}
+	hold := PutObjectLegalHoldCommand{Bucket: cmd.Bucket, Key: cmd.DestKey, VersionId: copied.VersionId, Hold: true}
+	if err := c.PutObjectLegalHold(ctx, hold); err != nil {
+		return result, fmt.Errorf("unable to lock promoted object: %w", err)
+	}
+	held, err := c.GetObjectLegalHold(ctx, GetObjectLegalHoldCommand{Bucket: cmd.Bucket, Key: cmd.DestKey, VersionId: copied.VersionId})
+	if err != nil {
+		return result, fmt.Errorf("unable to verify lock of promoted object: %w", err)
+	}
+	if !held {
+		return result, fmt.Errorf("promoted object %s is not locked", cmd.DestKey)
+	}
+	result.Immutable = true
+	return result, nil
+}
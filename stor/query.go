@@ -0,0 +1,148 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// QueryLanguage is the language of a query expression.
+type QueryLanguage string
+
+const (
+	// QueryLanguageSQL selects records with a SQL subset, e.g. `SELECT name FROM object WHERE age > 30`.
+	QueryLanguageSQL QueryLanguage = "sql"
+	// QueryLanguageJSONPath selects records with a JSONPath expression, e.g. `$[?(@.age > 30)].name`.
+	QueryLanguageJSONPath QueryLanguage = "jsonpath"
+)
+
+// QueryInputFormat is the format of the queried object.
+type QueryInputFormat string
+
+const (
+	// QueryInputCSV queries a CSV object. The first line holds the column names.
+	QueryInputCSV QueryInputFormat = "csv"
+	// QueryInputJSON queries an object with one JSON document per line.
+	QueryInputJSON QueryInputFormat = "json"
+)
+
+type QueryObjectCommand struct {
+	Bucket string
+	Key    string
+	// Expression selects the records to return.
+	Expression string
+	// Language of Expression. Defaults to QueryLanguageSQL.
+	Language QueryLanguage
+	// InputFormat is the format of the object. Defaults to QueryInputJSON.
+	InputFormat QueryInputFormat
+}
+
+type queryObjectRequest struct {
+	Expression  string           `json:"expression"`
+	Language    QueryLanguage    `json:"language"`
+	InputFormat QueryInputFormat `json:"inputFormat"`
+}
+
+// QueryObjectResult streams the records matching a query, one JSON document per line.
+// Clients are expected to close the result.
+type QueryObjectResult struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// QueryObject runs a query on a CSV or JSON object on the server and streams back the matching records,
+// so that only the selected data is transferred.
+// If the object cannot be found, the method returns ErrObjectNotFound.
+// Invalid expressions are rejected with ErrInvalidArgument.
+func (c *Client) QueryObject(ctx context.Context, cmd QueryObjectCommand) (*QueryObjectResult, error) {
+	if err := c.requireFeature(ctx, FeatureQuery); err != nil {
+		return nil, err
+	}
+	if cmd.Expression == "" {
+		return nil, fmt.Errorf("%w: expression must not be empty", ErrInvalidArgument)
+	}
+	if cmd.Language == "" {
+		cmd.Language = QueryLanguageSQL
+	}
+	if cmd.InputFormat == "" {
+		cmd.InputFormat = QueryInputJSON
+	}
+	body, err := json.Marshal(queryObjectRequest{
+		Expression:  cmd.Expression,
+		Language:    cmd.Language,
+		InputFormat: cmd.InputFormat,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("query", "")
+	res, err := c.streamReq(ctx, R{
+		op:          OperationQueryObject,
+		method:      "POST",
+		path:        objectPath(cmd.Bucket, cmd.Key),
+		query:       query,
+		contentType: "application/json",
+		body:        bytes.NewReader(body),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != 200 {
+		defer res.Body.Close()
+		var apiErr Error
+		_ = json.NewDecoder(io.LimitReader(res.Body, 4096)).Decode(&apiErr)
+		switch res.StatusCode {
+		case 400:
+			return nil, fmt.Errorf("%w: %s", ErrInvalidArgument, apiErr.Message)
+		case 404:
+			return nil, ErrObjectNotFound
+		default:
+			//TODO: map error
+			return nil, fmt.Errorf("unable to query object: %v", res.StatusCode)
+		}
+	}
+	return &QueryObjectResult{
+		body:   res.Body,
+		reader: bufio.NewReader(res.Body),
+	}, nil
+}
+
+// Read reads the raw record stream.
+func (r *QueryObjectResult) Read(p []byte) (int, error) {
+	return r.reader.Read(p)
+}
+
+// Next returns the next record. It returns io.EOF after the last record.
+// The returned slice is only valid until the next call.
+func (r *QueryObjectResult) Next() (json.RawMessage, error) {
+	for {
+		line, err := r.reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// records longer than the buffer are collected in full
+			line = append([]byte(nil), line...)
+			rest, err := r.reader.ReadBytes('\n')
+			line = append(line, rest...)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+		} else if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+	}
+}
+
+func (r *QueryObjectResult) Close() error {
+	return r.body.Close()
+}
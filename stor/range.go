@@ -0,0 +1,87 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidRange = fmt.Errorf("invalid range")
+
+// ByteRange is a range of bytes of an object.
+type ByteRange struct {
+	// Start is the offset of the first byte.
+	Start int64
+	// End is the offset of the last byte, inclusive. A negative End reads until the end of the object.
+	End int64
+}
+
+func (r ByteRange) header() string {
+	if r.End < 0 {
+		return fmt.Sprintf("bytes=%d-", r.Start)
+	}
+	return fmt.Sprintf("bytes=%d-%d", r.Start, r.End)
+}
+
+func (r ByteRange) validate() error {
+	if r.Start < 0 || (r.End >= 0 && r.End < r.Start) {
+		return fmt.Errorf("%w: %d-%d", ErrInvalidRange, r.Start, r.End)
+	}
+	return nil
+}
+
+// ContentRange is the range of an object returned by the server.
+type ContentRange struct {
+	// Start is the offset of the first byte.
+	Start int64
+	// End is the offset of the last byte, inclusive.
+	End int64
+	// Size is the size of the complete object. It is -1 if the server did not report the size.
+	Size int64
+}
+
+// parseContentRange parses a Content-Range header like "bytes 0-99/1000", "bytes 0-99/*" or "bytes */1000".
+func parseContentRange(header string) (*ContentRange, error) {
+	spec, ok := cutPrefix(header, "bytes ")
+	if !ok {
+		return nil, fmt.Errorf("invalid Content-Range: %q", header)
+	}
+	rangeSpec, sizeSpec, ok := strings.Cut(spec, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid Content-Range: %q", header)
+	}
+	cr := &ContentRange{Start: -1, End: -1, Size: -1}
+	if sizeSpec != "*" {
+		size, err := strconv.ParseInt(sizeSpec, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Content-Range: %q", header)
+		}
+		cr.Size = size
+	}
+	if rangeSpec == "*" {
+		return cr, nil
+	}
+	start, end, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid Content-Range: %q", header)
+	}
+	var err error
+	if cr.Start, err = strconv.ParseInt(start, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid Content-Range: %q", header)
+	}
+	if cr.End, err = strconv.ParseInt(end, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid Content-Range: %q", header)
+	}
+	return cr, nil
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
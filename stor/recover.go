@@ -0,0 +1,23 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// recoverPanic converts a panic into an ErrInternalClient error stored in err.
+// It must be deferred directly. The stack of the panic is written to logger, if set.
+func recoverPanic(logger Logger, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if logger != nil {
+		logger.Printf("stor: recovered from panic: %v\n%s", r, debug.Stack())
+	}
+	*err = fmt.Errorf("%w: %v", ErrInternalClient, r)
+}
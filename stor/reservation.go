@@ -0,0 +1,148 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrKeyReserved is returned by ReserveKey if the key is taken by an object or an active reservation.
+var ErrKeyReserved = fmt.Errorf("key reserved")
+
+const (
+	reservationTokenMeta   = "stor-reservation-token"
+	reservationExpiresMeta = "stor-reservation-expires"
+)
+
+// Reservation is a reservation of a key, created by ReserveKey.
+type Reservation struct {
+	Bucket    string
+	Key       string
+	Token     string
+	ExpiresAt time.Time
+	client    *Client
+}
+
+// ReserveKey reserves a key for ttl by atomically creating an empty marker object, so that a long upload does not
+// lose the key to a concurrent writer. Writers that use IfNoneMatch fail while the reservation exists; the owner
+// replaces the marker by writing the object without IfNoneMatch.
+//
+// Expired reservations are replaced. If the key is taken by an object or an active reservation,
+// ErrKeyReserved is returned.
+func (c *Client) ReserveKey(ctx context.Context, bucket, key string, ttl time.Duration) (*Reservation, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("unable to create reservation token: %v", err)
+	}
+	r := &Reservation{
+		Bucket:    bucket,
+		Key:       key,
+		Token:     hex.EncodeToString(token),
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+		client:    c,
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err := c.CreateObject(ctx, CreateObjectCommand{
+			Bucket:      bucket,
+			Key:         key,
+			ContentType: "application/octet-stream",
+			Data:        http.NoBody,
+			IfNoneMatch: true,
+			Metadata: map[string]string{
+				reservationTokenMeta:   r.Token,
+				reservationExpiresMeta: r.ExpiresAt.Format(time.RFC3339),
+			},
+		})
+		if err == nil {
+			return r, nil
+		}
+		if !errors.Is(err, ErrPreconditionFailed) {
+			return nil, err
+		}
+		if err := c.removeExpiredReservation(ctx, bucket, key); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyReserved, key)
+}
+
+// Release removes the reservation marker if the key is still held by this reservation.
+// Once the reserved object has been written, Release does nothing.
+//
+// If the server reports object generations, the marker is deleted only if its generation is unchanged, so an object
+// written concurrently by the owner is never removed. Otherwise, the check is advisory: an object written between the
+// check and the delete is deleted.
+func (r *Reservation) Release(ctx context.Context) error {
+	head, err := r.client.HeadObject(ctx, HeadObjectCommand{Bucket: r.Bucket, Key: r.Key})
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if head.Metadata[reservationTokenMeta] != r.Token {
+		return nil
+	}
+	_, err = r.client.deleteReservationMarker(ctx, r.Bucket, r.Key, head.Generation)
+	return err
+}
+
+// removeExpiredReservation deletes the object at key if it is an expired reservation marker.
+// Otherwise, ErrKeyReserved is returned. The delete is conditional in the same way as in Release.
+func (c *Client) removeExpiredReservation(ctx context.Context, bucket, key string) error {
+	head, err := c.HeadObject(ctx, HeadObjectCommand{Bucket: bucket, Key: key})
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	expires, err := time.Parse(time.RFC3339, head.Metadata[reservationExpiresMeta])
+	if head.Metadata[reservationTokenMeta] == "" || err != nil || time.Now().Before(expires) {
+		return fmt.Errorf("%w: %s", ErrKeyReserved, key)
+	}
+	deleted, err := c.deleteReservationMarker(ctx, bucket, key, head.Generation)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", ErrKeyReserved, key)
+	}
+	return nil
+}
+
+// deleteReservationMarker deletes the marker at key if its generation still matches. It reports false if the
+// marker has been replaced in the meantime. A generation of 0 means that the server does not report generations,
+// in which case the marker is deleted unconditionally.
+func (c *Client) deleteReservationMarker(ctx context.Context, bucket, key string, generation int64) (bool, error) {
+	if generation == 0 {
+		return true, c.deleteObject(ctx, bucket, key)
+	}
+	result, err := c.DeleteObjects(ctx, DeleteObjectsCommand{
+		Bucket:  bucket,
+		Objects: []ObjectReference{{Key: key, IfGenerationMatch: generation}},
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, r := range result.Results {
+		if r.Error == nil {
+			continue
+		}
+		// the delete is refused if the generation changed, tell that apart from other failures
+		head, err := c.HeadObject(ctx, HeadObjectCommand{Bucket: bucket, Key: key})
+		if errors.Is(err, ErrObjectNotFound) || (err == nil && head.Generation != generation) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to delete reservation %s: %s", r.Key, r.Error.Message)
+	}
+	return true, nil
+}
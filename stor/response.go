@@ -0,0 +1,71 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// ErrUnexpectedResponse is returned if a response cannot be decoded, e.g. because a proxy answered with an HTML page.
+var ErrUnexpectedResponse = fmt.Errorf("unexpected response")
+
+// maxResponseSnippet is the number of body bytes kept in an UnexpectedResponseError.
+const maxResponseSnippet = 256
+
+// UnexpectedResponseError describes a response that cannot be decoded. It matches ErrUnexpectedResponse.
+type UnexpectedResponseError struct {
+	StatusCode  int
+	ContentType string
+	// Body holds the first bytes of the response body.
+	Body []byte
+	// Err is the decoding error. It is nil if the response was rejected because of its content type.
+	Err error
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	msg := fmt.Sprintf("unexpected response: status %d, content type %q, body %q", e.StatusCode, e.ContentType, e.Body)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *UnexpectedResponseError) Is(target error) bool {
+	return target == ErrUnexpectedResponse
+}
+
+func (e *UnexpectedResponseError) Unwrap() error {
+	return e.Err
+}
+
+// unmarshalResponse decodes a JSON response body into v. If the client was created with
+// ClientOptions.SetStrictResponses, responses without a JSON content type are rejected before decoding.
+func (c *Client) unmarshalResponse(res *http.Response, body []byte, v interface{}) error {
+	contentType := res.Header.Get("Content-Type")
+	if c.strictResponses {
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+			return newUnexpectedResponseError(res, body, nil)
+		}
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return newUnexpectedResponseError(res, body, err)
+	}
+	return nil
+}
+
+func newUnexpectedResponseError(res *http.Response, body []byte, err error) error {
+	if len(body) > maxResponseSnippet {
+		body = body[:maxResponseSnippet]
+	}
+	return &UnexpectedResponseError{
+		StatusCode:  res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+		Body:        append([]byte(nil), body...),
+		Err:         err,
+	}
+}
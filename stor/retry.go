@@ -0,0 +1,153 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// ErrorClass is the classification of an error that drives the retry decision.
+type ErrorClass string
+
+const (
+	// ErrorClassServiceUnavailable means the server was temporarily unable to handle the request. It is retried.
+	ErrorClassServiceUnavailable ErrorClass = "service_unavailable"
+	// ErrorClassTimeout means the request timed out.
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassCanceled means the context of the operation was canceled.
+	ErrorClassCanceled ErrorClass = "canceled"
+	// ErrorClassNetwork means the connection to the server failed.
+	ErrorClassNetwork ErrorClass = "network"
+	// ErrorClassOther covers all other errors, e.g. invalid arguments or unexpected responses.
+	ErrorClassOther ErrorClass = "other"
+)
+
+// ClassifyError returns the class of err.
+func ClassifyError(err error) ErrorClass {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrServiceUnavailable):
+		return ErrorClassServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	default:
+		return ErrorClassOther
+	}
+}
+
+// retryable reports whether errors of the class are retried.
+func (c ErrorClass) retryable() bool {
+	return c == ErrorClassServiceUnavailable
+}
+
+// retryablePart reports whether a failed part upload is retried. As a part can be read again from its source,
+// uploads that failed mid-stream because of the network or a timeout are retried as well.
+func retryablePart(c ErrorClass) bool {
+	return c == ErrorClassServiceUnavailable || c == ErrorClassNetwork || c == ErrorClassTimeout
+}
+
+// RetryInfo describes a retry decision for a failed operation.
+type RetryInfo struct {
+	// Operation is the name of the operation, e.g. OperationListObjects.
+	Operation OperationName
+	// Bucket is the bucket the operation was called on.
+	Bucket string
+	// Attempt is the number of the upcoming attempt, starting at 2 for the first retry.
+	Attempt int
+	// MaxAttempts is the maximum number of attempts of the operation.
+	MaxAttempts int
+	// Retry is false if the client gives up, because the error is not retryable or the attempts are exhausted.
+	Retry bool
+	// Delay is the time the client waits before the attempt. It is 0 if Retry is false.
+	Delay time.Duration
+	// Err is the error of the previous attempt.
+	Err error
+	// Class is the classification of Err.
+	Class ErrorClass
+	// Causes is the chain of errors wrapped by Err, starting with Err itself.
+	Causes []error
+}
+
+// errorChain returns err followed by the errors it wraps.
+func errorChain(err error) []error {
+	chain := make([]error, 0, 4)
+	for err != nil {
+		chain = append(chain, err)
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
+
+// retryDelay returns the exponential backoff delay before the given attempt.
+func retryDelay(attempt int) time.Duration {
+	d := retryBaseDelay
+	for i := 2; i < attempt && d < retryMaxDelay; i++ {
+		d *= 2
+	}
+	if d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return d
+}
+
+// retry runs fn until it succeeds, fails with an error that is not retryable, or maxRetries is exhausted.
+// Every decision on a failed attempt is reported to Hooks.OnRetry.
+func (c *Client) retry(ctx context.Context, op OperationName, bucket string, maxRetries int, fn func() error) error {
+	return c.retryWhen(ctx, op, bucket, maxRetries, ErrorClass.retryable, fn)
+}
+
+// retryWhen is like retry, but retries errors of the classes accepted by retryable.
+func (c *Client) retryWhen(ctx context.Context, op OperationName, bucket string, maxRetries int, retryable func(ErrorClass) bool, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		class := ClassifyError(err)
+		retry := retryable(class) && attempt <= maxRetries && ctx.Err() == nil
+		delay := time.Duration(0)
+		if retry {
+			delay = retryDelay(attempt + 1)
+		}
+		if c.hooks.OnRetry != nil {
+			c.hooks.OnRetry(RetryInfo{
+				Operation:   op,
+				Bucket:      bucket,
+				Attempt:     attempt + 1,
+				MaxAttempts: maxRetries + 1,
+				Retry:       retry,
+				Delay:       delay,
+				Err:         err,
+				Class:       class,
+				Causes:      errorChain(err),
+			})
+		}
+		if !retry {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
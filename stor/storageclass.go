@@ -0,0 +1,50 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// StorageClass is the storage tier of an object.
+type StorageClass string
+
+const (
+	// StorageClassStandard stores objects for frequent access. It is the default of the server.
+	StorageClassStandard StorageClass = "STANDARD"
+	// StorageClassCold stores rarely accessed objects at a lower cost and with slower access.
+	StorageClassCold StorageClass = "COLD"
+)
+
+const storageClassHeader = "Stor-Storage-Class"
+
+// setStorageClassHeader sets the storage class of an object, if one is given.
+func (c *Client) setStorageClassHeader(ctx context.Context, header http.Header, class StorageClass) error {
+	if class == "" {
+		return nil
+	}
+	if err := c.requireFeature(ctx, FeatureStorageClasses); err != nil {
+		return err
+	}
+	header.Set(storageClassHeader, string(class))
+	return nil
+}
+
+// TransitionObject moves an object to another storage class, e.g. to demote old objects to cheaper storage.
+// The object is copied onto itself on the server, keeping its content type and metadata.
+// If the object cannot be found, the method returns ErrObjectNotFound.
+func (c *Client) TransitionObject(ctx context.Context, bucket, key string, class StorageClass) (*CreateObjectResult, error) {
+	if class == "" {
+		return nil, fmt.Errorf("%w: storage class must not be empty", ErrInvalidArgument)
+	}
+	return c.CopyObject(ctx, CopyObjectCommand{
+		Bucket:       bucket,
+		SourceKey:    key,
+		DestKey:      key,
+		StorageClass: class,
+	})
+}
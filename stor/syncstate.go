@@ -0,0 +1,124 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncStateVersion is the version of the sync state file format written by SyncState.Save.
+const SyncStateVersion = 1
+
+// SyncEntry is the recorded state of a single synchronized object.
+type SyncEntry struct {
+	ETag    string    `json:"etag"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// SyncState records the ETag, size and modification time of synchronized objects by key.
+// It allows incremental syncs to skip unchanged local files without hashing them.
+//
+// The state is stored as a JSON document of the form
+//
+//	{
+//	  "version": 1,
+//	  "bucket": "my-bucket",
+//	  "entries": {
+//	    "path/to/key": {"etag": "...", "size": 42, "mtime": "2024-01-02T15:04:05Z"}
+//	  }
+//	}
+//
+// Modification times use RFC 3339 with nanosecond precision.
+type SyncState struct {
+	Version int                   `json:"version"`
+	Bucket  string                `json:"bucket"`
+	Entries map[string]*SyncEntry `json:"entries"`
+}
+
+// NewSyncState creates an empty sync state for the given bucket.
+func NewSyncState(bucket string) *SyncState {
+	return &SyncState{
+		Version: SyncStateVersion,
+		Bucket:  bucket,
+		Entries: make(map[string]*SyncEntry),
+	}
+}
+
+// LoadSyncState reads a sync state from the file at path.
+// If the file does not exist, an empty state for bucket is returned.
+func LoadSyncState(path string, bucket string) (*SyncState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewSyncState(bucket), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read sync state: %v", err)
+	}
+	var state SyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal sync state: %v", err)
+	}
+	if state.Version != SyncStateVersion {
+		return nil, fmt.Errorf("unsupported sync state version: %d", state.Version)
+	}
+	if state.Bucket != bucket {
+		return nil, fmt.Errorf("sync state belongs to bucket %q", state.Bucket)
+	}
+	if state.Entries == nil {
+		state.Entries = make(map[string]*SyncEntry)
+	}
+	return &state, nil
+}
+
+// Save writes the sync state to the file at path.
+// The file is replaced atomically, so an interrupted save never leaves a truncated state behind.
+func (s *SyncState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal sync state: %v", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("unable to save sync state: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to save sync state: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to save sync state: %v", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("unable to save sync state: %v", err)
+	}
+	return nil
+}
+
+// Unchanged reports whether key was recorded with the given size and modification time.
+// If so, the recorded ETag is returned.
+func (s *SyncState) Unchanged(key string, size int64, modTime time.Time) (string, bool) {
+	e, ok := s.Entries[key]
+	if !ok || e.Size != size || !e.ModTime.Equal(modTime) {
+		return "", false
+	}
+	return e.ETag, true
+}
+
+// Set records the state of key.
+func (s *SyncState) Set(key string, etag string, size int64, modTime time.Time) {
+	s.Entries[key] = &SyncEntry{ETag: etag, Size: size, ModTime: modTime.UTC()}
+}
+
+// Delete removes key from the state.
+func (s *SyncState) Delete(key string) {
+	delete(s.Entries, key)
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"fmt"
+	"strings"
+)
+
+var ErrTenantViolation = fmt.Errorf("tenant violation")
+
+// TenantPolicy restricts the buckets and keys a client may use.
+// Requests that violate the policy fail locally with ErrTenantViolation before they are sent.
+//
+// Listing buckets is not restricted, as the server does not allow filtering buckets by prefix.
+type TenantPolicy struct {
+	// BucketPrefix is the prefix every bucket name must start with.
+	BucketPrefix string
+	// KeyPrefix is the prefix every object key and listing prefix must start with.
+	KeyPrefix string
+}
+
+func (p *TenantPolicy) checkBucket(bucket string) error {
+	if p == nil || strings.HasPrefix(bucket, p.BucketPrefix) {
+		return nil
+	}
+	return fmt.Errorf("%w: bucket %q does not start with %q", ErrTenantViolation, bucket, p.BucketPrefix)
+}
+
+func (p *TenantPolicy) checkKey(key string) error {
+	if p == nil || strings.HasPrefix(key, p.KeyPrefix) {
+		return nil
+	}
+	return fmt.Errorf("%w: key %q does not start with %q", ErrTenantViolation, key, p.KeyPrefix)
+}
+
+// checkRequest checks the bucket and key addressed by a request, the prefix of listings and the source of copies.
+func (p *TenantPolicy) checkRequest(r R) error {
+	if p == nil || r.path == "" {
+		return nil
+	}
+	bucket, key, hasKey := strings.Cut(r.path, "/")
+	if err := p.checkBucket(bucket); err != nil {
+		return err
+	}
+	if hasKey {
+		if err := p.checkKey(key); err != nil {
+			return err
+		}
+	} else if r.method == "" || r.method == "GET" {
+		if err := p.checkKey(r.query.Get("prefix")); err != nil {
+			return err
+		}
+	}
+	if source := r.header.Get("Stor-Copy-Source"); source != "" {
+		if err := p.checkKey(source); err != nil {
+			return err
+		}
+	}
+	if sourceBucket := r.header.Get("Stor-Copy-Source-Bucket"); sourceBucket != "" {
+		if err := p.checkBucket(sourceBucket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TokenVerb is an action a scoped token allows.
+type TokenVerb string
+
+const (
+	TokenVerbRead   TokenVerb = "read"
+	TokenVerbWrite  TokenVerb = "write"
+	TokenVerbDelete TokenVerb = "delete"
+	TokenVerbList   TokenVerb = "list"
+)
+
+type CreateScopedTokenCommand struct {
+	Bucket string
+	// Prefix restricts the token to keys below the prefix. If empty, the token is valid for the whole bucket.
+	Prefix string
+	// Verbs are the actions the token allows. At least one verb is required.
+	Verbs []TokenVerb
+	TTL   time.Duration
+}
+
+type CreateScopedTokenResult struct {
+	// Token is used as bearer token in place of an API key.
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type createScopedTokenRequest struct {
+	Bucket string      `json:"bucket"`
+	Prefix string      `json:"prefix,omitempty"`
+	Verbs  []TokenVerb `json:"verbs"`
+	TTL    int64       `json:"ttl"`
+}
+
+// CreateScopedToken exchanges the API key of the client for a short-lived token that is restricted to a bucket,
+// a key prefix and a set of verbs, e.g. to hand narrow credentials to a frontend.
+func (c *Client) CreateScopedToken(ctx context.Context, cmd CreateScopedTokenCommand) (*CreateScopedTokenResult, error) {
+	if err := c.requireFeature(ctx, FeatureScopedTokens); err != nil {
+		return nil, err
+	}
+	if cmd.Bucket == "" {
+		return nil, fmt.Errorf("%w: bucket must not be empty", ErrInvalidArgument)
+	}
+	if len(cmd.Verbs) == 0 {
+		return nil, fmt.Errorf("%w: at least one verb is required", ErrInvalidArgument)
+	}
+	if cmd.TTL < time.Second {
+		return nil, fmt.Errorf("%w: ttl must be at least one second", ErrInvalidArgument)
+	}
+	if err := c.tenantPolicy.checkBucket(cmd.Bucket); err != nil {
+		return nil, err
+	}
+	if err := c.tenantPolicy.checkKey(cmd.Prefix); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(createScopedTokenRequest{
+		Bucket: cmd.Bucket,
+		Prefix: cmd.Prefix,
+		Verbs:  cmd.Verbs,
+		TTL:    int64(cmd.TTL.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("tokens", "")
+	res, responseBody, err := c.doReq(ctx, R{
+		op:          OperationCreateScopedToken,
+		method:      "POST",
+		query:       query,
+		contentType: "application/json",
+		body:        bytes.NewReader(body),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != 201 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to create scoped token: %v", res.StatusCode)
+	}
+
+	var result CreateScopedTokenResult
+	if err := c.unmarshalResponse(res, responseBody, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
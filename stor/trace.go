@@ -0,0 +1,136 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// RequestTiming is the timing breakdown of a single request.
+type RequestTiming struct {
+	// DNS is the time spent resolving the host name.
+	DNS time.Duration
+	// Connect is the time spent establishing the TCP connection.
+	Connect time.Duration
+	// TLS is the time spent on the TLS handshake.
+	TLS time.Duration
+	// FirstByte is the time from the start of the request until the first response byte was received.
+	FirstByte time.Duration
+	// Body is the time spent receiving the response body.
+	Body time.Duration
+	// Total is the total duration of the request.
+	Total time.Duration
+}
+
+// requestTracer records the timing of a request. The httptrace callbacks are invoked from transport goroutines,
+// concurrently when several addresses are dialed, so all fields are guarded by mu.
+type requestTracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	firstByte    time.Time
+	timing       RequestTiming
+}
+
+func newRequestTracer() *requestTracer {
+	return &requestTracer{start: time.Now()}
+}
+
+// record runs fn with the lock of the tracer held.
+func (t *requestTracer) record(fn func(now time.Time)) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(now)
+}
+
+func (t *requestTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.record(func(now time.Time) { t.dnsStart = now })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.record(func(now time.Time) { t.timing.DNS = now.Sub(t.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			t.record(func(now time.Time) {
+				// with several dial attempts, the connect time is measured from the first one
+				if t.connectStart.IsZero() {
+					t.connectStart = now
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err != nil {
+				return
+			}
+			t.record(func(now time.Time) { t.timing.Connect = now.Sub(t.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			t.record(func(now time.Time) { t.tlsStart = now })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.record(func(now time.Time) { t.timing.TLS = now.Sub(t.tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			t.record(func(now time.Time) {
+				t.firstByte = now
+				t.timing.FirstByte = now.Sub(t.start)
+			})
+		},
+	}
+}
+
+// finish completes the timing once the response body has been consumed.
+func (t *requestTracer) finish() RequestTiming {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.firstByte.IsZero() {
+		t.timing.Body = now.Sub(t.firstByte)
+	}
+	t.timing.Total = now.Sub(t.start)
+	return t.timing
+}
+
+// tracedBody completes the request timing once the response body is closed.
+type tracedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// requestDone reports a completed request to the configured hooks and logs it if it was slow.
+func (c *Client) requestDone(tracer *requestTracer, method, path string, statusCode int, err error) {
+	timing := tracer.finish()
+	if c.hooks.OnRequest != nil {
+		c.hooks.OnRequest(RequestInfo{
+			Method:     method,
+			Path:       path,
+			StatusCode: statusCode,
+			Err:        err,
+			Timing:     timing,
+		})
+	}
+	if c.slowRequestThreshold <= 0 || timing.Total < c.slowRequestThreshold {
+		return
+	}
+	c.logger.Printf(
+		"stor: slow request method=%s path=%s total=%v dns=%v connect=%v tls=%v ttfb=%v body=%v",
+		method, "/"+path, timing.Total, timing.DNS, timing.Connect, timing.TLS, timing.FirstByte, timing.Body,
+	)
+}
@@ -0,0 +1,432 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+type UploaderOptions struct {
+	// PartSize is the size of the parts. Defaults to the minimum part size of the server, or the smallest size
+	// that satisfies the part limits if the size of the uploaded data is known.
+	PartSize int64
+	// Concurrency limits the number of parts uploaded at the same time. Defaults to 4.
+	// Every concurrently uploaded part is buffered in memory.
+	Concurrency int
+	// LeavePartsOnError keeps the uploaded parts of a failed multipart upload instead of aborting the upload,
+	// e.g. to resume it later with ResumeUpload. The id of the upload is returned in a *MultipartUploadError.
+	LeavePartsOnError bool
+	// PartRetries is how often a failed part is uploaded again, reading it anew from the buffered data.
+	// Defaults to DefaultPartRetries if 0. If negative, parts are not retried.
+	PartRetries int
+	// ChecksumAlgorithm makes the client compute a checksum of every part, or of the data if it is uploaded with
+	// a single request. Multipart uploads are completed with a composite checksum of the parts.
+	ChecksumAlgorithm ChecksumAlgorithm
+}
+
+// DefaultPartRetries is the default number of retries of a failed part upload.
+const DefaultPartRetries = 3
+
+// Uploader uploads data of any size from an io.Reader. Data that fits into a single part is uploaded with
+// a single request, larger data with a concurrent multipart upload.
+type Uploader struct {
+	client            *Client
+	partSize          int64
+	concurrency       int
+	leavePartsOnError bool
+	partRetries       int
+	checksumAlgorithm ChecksumAlgorithm
+}
+
+type UploadInput struct {
+	Bucket      string
+	Key         string
+	ContentType string
+	// Metadata is user-defined metadata that is stored with the object.
+	Metadata map[string]string
+	// ContentEncoding is the encoding of Body, e.g. "gzip" for pre-compressed assets.
+	ContentEncoding string
+	// Expires makes the server delete the object once the time has passed.
+	Expires time.Time
+	Body    io.Reader
+}
+
+type UploadOutput struct {
+	ETag string
+	Size int64
+	// UploadId is the id of the multipart upload. It is empty if the data was uploaded with a single request.
+	UploadId string
+	// Checksum is the base64 encoded checksum of the data, or the composite checksum of a multipart upload,
+	// if a ChecksumAlgorithm was configured.
+	Checksum string
+}
+
+// NewUploader creates a new Uploader.
+func (c *Client) NewUploader(opts UploaderOptions) *Uploader {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	return &Uploader{
+		client:            c,
+		partSize:          opts.PartSize,
+		concurrency:       opts.Concurrency,
+		leavePartsOnError: opts.LeavePartsOnError,
+		partRetries:       partRetries(opts.PartRetries),
+		checksumAlgorithm: opts.ChecksumAlgorithm,
+	}
+}
+
+// Upload uploads the content of input.Body. If a part fails, the remaining parts are canceled and the multipart
+// upload is aborted, also if ctx is canceled. Errors of multipart uploads are returned as *MultipartUploadError.
+func (u *Uploader) Upload(ctx context.Context, input UploadInput) (*UploadOutput, error) {
+	partSize := u.partSize
+	if partSize <= 0 {
+		partSize = u.client.limits.MinPartSize
+		if size := readerLen(input.Body); size > 0 {
+			partSize = u.client.limits.partSizeFor(size)
+		}
+	}
+
+	first, err := readPart(input.Body, partSize)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("unable to read upload data: %w", err)
+	}
+	if err == io.EOF {
+		res, err := u.client.CreateObject(ctx, CreateObjectCommand{
+			Bucket:            input.Bucket,
+			Key:               input.Key,
+			ContentType:       input.ContentType,
+			Data:              bytes.NewReader(first),
+			ContentLength:     int64(len(first)),
+			Metadata:          input.Metadata,
+			ContentEncoding:   input.ContentEncoding,
+			Expires:           input.Expires,
+			ChecksumAlgorithm: u.checksumAlgorithm,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &UploadOutput{ETag: res.ETag, Size: int64(len(first)), Checksum: res.Checksum}, nil
+	}
+
+	upload, err := u.client.CreateMultipartUpload(ctx, CreateMultipartUploadCommand{
+		Bucket:          input.Bucket,
+		Key:             input.Key,
+		ContentType:     input.ContentType,
+		Metadata:        input.Metadata,
+		ContentEncoding: input.ContentEncoding,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m := &multipartUpload{
+		client:   u.client,
+		bucket:   input.Bucket,
+		key:      input.Key,
+		uploadId: upload.UploadId,
+		retries:  u.partRetries,
+		checksum: u.checksumAlgorithm,
+	}
+	size, err := m.run(ctx, u.concurrency, first, input.Body, partSize)
+	if err != nil {
+		return nil, u.client.failMultipartUpload(ctx, input.Bucket, input.Key, upload.UploadId, u.leavePartsOnError, err)
+	}
+	res, err := u.client.CompleteMultipartUpload(ctx, CompleteMultipartUploadCommand{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		UploadId:          upload.UploadId,
+		Parts:             m.parts,
+		Expires:           input.Expires,
+		ChecksumAlgorithm: u.checksumAlgorithm,
+	})
+	if err != nil {
+		return nil, u.client.failMultipartUpload(ctx, input.Bucket, input.Key, upload.UploadId, u.leavePartsOnError, err)
+	}
+	return &UploadOutput{ETag: res.ETag, Size: size, UploadId: upload.UploadId, Checksum: res.Checksum}, nil
+}
+
+// ResumeUpload resumes the multipart upload uploadId, e.g. one left behind by a failed Upload with
+// LeavePartsOnError, possibly in another process. The parts that have been uploaded are listed from the server
+// and only the missing parts are read from source and uploaded, before the upload is completed.
+//
+// source must contain the same data as the original upload. The part size is taken from the options, or else
+// from the largest uploaded part. Uploaded parts whose size does not match the data of source are uploaded again.
+// Errors are handled the same way as in Upload.
+func (u *Uploader) ResumeUpload(ctx context.Context, bucket, key, uploadId string, source io.ReaderAt) (*UploadOutput, error) {
+	uploaded, err := u.client.listAllParts(ctx, bucket, key, uploadId)
+	if err != nil {
+		return nil, err
+	}
+	partSize := u.partSize
+	if partSize <= 0 {
+		partSize = u.client.limits.MinPartSize
+		for _, p := range uploaded {
+			if p.Size > partSize {
+				partSize = p.Size
+			}
+		}
+	}
+
+	r := io.NewSectionReader(source, 0, math.MaxInt64)
+	first, err := readPart(r, partSize)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("unable to read upload data: %w", err)
+	}
+	m := &multipartUpload{
+		client:   u.client,
+		bucket:   bucket,
+		key:      key,
+		uploadId: uploadId,
+		retries:  u.partRetries,
+		checksum: u.checksumAlgorithm,
+		uploaded: uploaded,
+	}
+	size, err := m.run(ctx, u.concurrency, first, r, partSize)
+	if err != nil {
+		return nil, u.client.failMultipartUpload(ctx, bucket, key, uploadId, u.leavePartsOnError, err)
+	}
+	res, err := u.client.CompleteMultipartUpload(ctx, CompleteMultipartUploadCommand{
+		Bucket:            bucket,
+		Key:               key,
+		UploadId:          uploadId,
+		Parts:             m.parts,
+		ChecksumAlgorithm: u.checksumAlgorithm,
+	})
+	if err != nil {
+		return nil, u.client.failMultipartUpload(ctx, bucket, key, uploadId, u.leavePartsOnError, err)
+	}
+	return &UploadOutput{ETag: res.ETag, Size: size, UploadId: uploadId, Checksum: res.Checksum}, nil
+}
+
+// listAllParts lists all uploaded parts of a multipart upload by their part number.
+func (c *Client) listAllParts(ctx context.Context, bucket, key, uploadId string) (map[int]*Part, error) {
+	parts := make(map[int]*Part)
+	marker := 0
+	for {
+		res, err := c.ListParts(ctx, ListPartsCommand{
+			Bucket:           bucket,
+			Key:              key,
+			UploadId:         uploadId,
+			PartNumberMarker: marker,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range res.Parts {
+			parts[p.PartNumber] = p
+		}
+		if !res.IsTruncated || res.NextPartNumberMarker <= marker {
+			return parts, nil
+		}
+		marker = res.NextPartNumberMarker
+	}
+}
+
+// multipartUpload uploads the parts of a multipart upload concurrently.
+type multipartUpload struct {
+	client   *Client
+	bucket   string
+	key      string
+	uploadId string
+	retries  int
+	checksum ChecksumAlgorithm
+	// uploaded are the parts that are already on the server when an upload is resumed.
+	uploaded map[int]*Part
+
+	mu    sync.Mutex
+	parts []PartReference
+	err   error
+}
+
+// run uploads first and the rest of r in parts of partSize. It returns the total size of the uploaded data.
+func (m *multipartUpload) run(ctx context.Context, concurrency int, first []byte, r io.Reader, partSize int64) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	size := int64(0)
+	data := first
+	var readErr error
+	for partNumber := 1; ; partNumber++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		size += int64(len(data))
+		wg.Add(1)
+		go func(partNumber int, data []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := m.uploadPart(ctx, partNumber, data); err != nil {
+				m.fail(err)
+				cancel()
+			}
+		}(partNumber, data)
+
+		if readErr == io.EOF {
+			break
+		}
+		data, readErr = readPart(r, partSize)
+		if readErr != nil && readErr != io.EOF {
+			// stop the parts in flight, like a failed part does
+			m.fail(fmt.Errorf("unable to read upload data: %w", readErr))
+			cancel()
+			break
+		}
+		if readErr == io.EOF && len(data) == 0 {
+			break
+		}
+	}
+	wg.Wait()
+
+	if m.err != nil {
+		return 0, m.err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	sort.Slice(m.parts, func(i, j int) bool {
+		return m.parts[i].PartNumber < m.parts[j].PartNumber
+	})
+	return size, nil
+}
+
+func (m *multipartUpload) uploadPart(ctx context.Context, partNumber int, data []byte) (err error) {
+	defer recoverPanic(m.client.logger, &err)
+	if p, ok := m.uploaded[partNumber]; ok && p.Size == int64(len(data)) {
+		return m.skipPart(p, data)
+	}
+	res, err := m.client.uploadPartRetry(ctx, UploadPartCommand{
+		Bucket:            m.bucket,
+		Key:               m.key,
+		UploadId:          m.uploadId,
+		PartNumber:        partNumber,
+		ContentLength:     int64(len(data)),
+		ChecksumAlgorithm: m.checksum,
+	}, func() io.Reader {
+		return bytes.NewReader(data)
+	}, m.retries)
+	if err != nil {
+		return fmt.Errorf("unable to upload part %d: %w", partNumber, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parts = append(m.parts, PartReference{ETag: res.ETag, PartNumber: partNumber, Checksum: res.Checksum})
+	return nil
+}
+
+// skipPart records a part that is already on the server. Its checksum is computed from the local data,
+// since ListParts does not report it.
+func (m *multipartUpload) skipPart(p *Part, data []byte) error {
+	ref := PartReference{ETag: p.ETag, PartNumber: p.PartNumber}
+	if m.checksum != "" {
+		h, err := m.checksum.newHash()
+		if err != nil {
+			return err
+		}
+		h.Write(data)
+		ref.Checksum = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parts = append(m.parts, ref)
+	return nil
+}
+
+// fail records the first error of the upload.
+func (m *multipartUpload) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+// uploadPartRetry uploads a part and retries it up to retries times if it failed because of the network,
+// a timeout or an unavailable server. newReader is called for every attempt and must return the data of the part
+// from its start.
+func (c *Client) uploadPartRetry(ctx context.Context, cmd UploadPartCommand, newReader func() io.Reader, retries int) (*UploadPartResponse, error) {
+	var res *UploadPartResponse
+	err := c.retryWhen(ctx, OperationUploadPart, cmd.Bucket, retries, retryablePart, func() error {
+		cmd.Data = newReader()
+		var err error
+		res, err = c.UploadPart(ctx, cmd)
+		return err
+	})
+	return res, err
+}
+
+// partRetries returns the number of part retries for an option value.
+func partRetries(retries int) int {
+	if retries == 0 {
+		return DefaultPartRetries
+	}
+	if retries < 0 {
+		return 0
+	}
+	return retries
+}
+
+// MultipartUploadError is returned if a multipart upload failed after it was created.
+type MultipartUploadError struct {
+	// UploadId is the id of the failed upload.
+	UploadId string
+	// Aborted reports whether the upload was aborted. If not, its parts remain on the server until the upload
+	// is completed or aborted.
+	Aborted bool
+	Err     error
+}
+
+func (e *MultipartUploadError) Error() string {
+	return fmt.Sprintf("multipart upload %s failed: %v", e.UploadId, e.Err)
+}
+
+func (e *MultipartUploadError) Unwrap() error {
+	return e.Err
+}
+
+// failMultipartUpload aborts a failed upload unless leaveParts is set and returns a *MultipartUploadError.
+func (c *Client) failMultipartUpload(ctx context.Context, bucket, key, uploadId string, leaveParts bool, err error) error {
+	uploadErr := &MultipartUploadError{UploadId: uploadId, Err: err}
+	if leaveParts {
+		return uploadErr
+	}
+	if ctx.Err() != nil {
+		// canceled uploads must be aborted as well
+		ctx = context.Background()
+	}
+	if abortErr := c.AbortMultipartUpload(ctx, AbortMultipartUploadCommand{
+		Bucket:   bucket,
+		Key:      key,
+		UploadId: uploadId,
+	}); abortErr != nil {
+		c.logger.Printf("stor: unable to abort multipart upload bucket=%s key=%s upload=%s: %v", bucket, key, uploadId, abortErr)
+		return uploadErr
+	}
+	uploadErr.Aborted = true
+	return uploadErr
+}
+
+// readPart reads up to size bytes from r. It returns io.EOF with the remaining data once r is exhausted.
+func readPart(r io.Reader, size int64) ([]byte, error) {
+	buf := make([]byte, size)
+	n, err := io.ReadFull(r, buf)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return buf[:n], err
+}
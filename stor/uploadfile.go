@@ -0,0 +1,168 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// DefaultMultipartThreshold is the file size from which UploadFile uses a multipart upload.
+const DefaultMultipartThreshold = 64 << 20
+
+type UploadFileOptions struct {
+	// ContentType of the object. If empty, it is derived from the file extension or sniffed from the content.
+	ContentType string
+	// CacheControl is served with downloads of the object. It is only supported for single request uploads.
+	CacheControl string
+	// Metadata is user-defined metadata that is stored with the object.
+	Metadata map[string]string
+	// MultipartThreshold is the file size from which a multipart upload is used. Defaults to DefaultMultipartThreshold.
+	MultipartThreshold int64
+	// PartSize is the size of the parts of a multipart upload. Defaults to the smallest size that
+	// satisfies the part limits of the server.
+	PartSize int64
+	// LeavePartsOnError keeps the uploaded parts of a failed multipart upload instead of aborting the upload.
+	// The id of the upload is returned in a *MultipartUploadError.
+	LeavePartsOnError bool
+	// PartRetries is how often a failed part is uploaded again, reading it anew from the file.
+	// Defaults to DefaultPartRetries if 0. If negative, parts are not retried.
+	PartRetries int
+	// ChecksumAlgorithm makes the client compute a checksum of the file, or of every part of a multipart upload.
+	// Multipart uploads are completed with a composite checksum of the parts.
+	ChecksumAlgorithm ChecksumAlgorithm
+}
+
+type UploadFileResult struct {
+	ETag string
+	Size int64
+	// Checksum is the base64 encoded checksum of the file, or the composite checksum of a multipart upload,
+	// if a ChecksumAlgorithm was configured.
+	Checksum string
+}
+
+// UploadFile uploads the file at path as an object. Large files are uploaded with a multipart upload,
+// which is aborted if the upload fails or ctx is canceled.
+func (c *Client) UploadFile(ctx context.Context, bucket, key, path string, opts UploadFileOptions) (*UploadFileResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat file: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: %s is not a regular file", ErrInvalidArgument, path)
+	}
+	size := info.Size()
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType, err = detectContentType(f, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	threshold := opts.MultipartThreshold
+	if threshold <= 0 {
+		threshold = DefaultMultipartThreshold
+	}
+	if size < threshold {
+		res, err := c.CreateObject(ctx, CreateObjectCommand{
+			Bucket:            bucket,
+			Key:               key,
+			ContentType:       contentType,
+			Data:              f,
+			ContentLength:     size,
+			CacheControl:      opts.CacheControl,
+			Metadata:          opts.Metadata,
+			ChecksumAlgorithm: opts.ChecksumAlgorithm,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &UploadFileResult{ETag: res.ETag, Size: size, Checksum: res.Checksum}, nil
+	}
+
+	partSize := opts.PartSize
+	if partSize <= 0 {
+		partSize = c.limits.partSizeFor(size)
+	}
+	upload, err := c.CreateMultipartUpload(ctx, CreateMultipartUploadCommand{
+		Bucket:      bucket,
+		Key:         key,
+		ContentType: contentType,
+		Metadata:    opts.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.uploadParts(ctx, bucket, key, upload.UploadId, f, size, partSize, partRetries(opts.PartRetries), opts.ChecksumAlgorithm)
+	if err != nil {
+		return nil, c.failMultipartUpload(ctx, bucket, key, upload.UploadId, opts.LeavePartsOnError, err)
+	}
+	return &UploadFileResult{ETag: res.ETag, Size: size, Checksum: res.Checksum}, nil
+}
+
+// uploadParts uploads size bytes of r in parts of partSize and completes the multipart upload.
+// Failed parts are read again from r and retried up to retries times. If algorithm is set, the parts are uploaded
+// with checksums and the upload is completed with their composite checksum.
+func (c *Client) uploadParts(ctx context.Context, bucket, key, uploadId string, r io.ReaderAt, size, partSize int64, retries int, algorithm ChecksumAlgorithm) (*CompleteMultipartUploadResult, error) {
+	parts := make([]PartReference, 0, (size+partSize-1)/partSize)
+	for offset := int64(0); offset < size; offset += partSize {
+		n := partSize
+		if offset+n > size {
+			n = size - offset
+		}
+		partNumber := len(parts) + 1
+		offset := offset
+		res, err := c.uploadPartRetry(ctx, UploadPartCommand{
+			Bucket:            bucket,
+			Key:               key,
+			UploadId:          uploadId,
+			PartNumber:        partNumber,
+			ContentLength:     n,
+			ChecksumAlgorithm: algorithm,
+		}, func() io.Reader {
+			return io.NewSectionReader(r, offset, n)
+		}, retries)
+		if err != nil {
+			return nil, fmt.Errorf("unable to upload part %d: %w", partNumber, err)
+		}
+		parts = append(parts, PartReference{ETag: res.ETag, PartNumber: partNumber, Checksum: res.Checksum})
+	}
+	return c.CompleteMultipartUpload(ctx, CompleteMultipartUploadCommand{
+		Bucket:            bucket,
+		Key:               key,
+		UploadId:          uploadId,
+		Parts:             parts,
+		ChecksumAlgorithm: algorithm,
+	})
+}
+
+// detectContentType derives the content type from the file extension and falls back to sniffing the content.
+// The file offset is reset to the start.
+func detectContentType(f *os.File, path string) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType, nil
+	}
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("unable to read file: %v", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("unable to read file: %v", err)
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
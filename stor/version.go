@@ -0,0 +1,109 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+)
+
+// APIVersion is the version of the STOR API implemented by this client.
+// It is sent with every request in the X-Stor-Api-Version header.
+const APIVersion = 1
+
+const apiVersionHeader = "X-Stor-Api-Version"
+
+var ErrIncompatibleServer = fmt.Errorf("incompatible server")
+
+// Feature is a server feature that requires a minimum server API version.
+type Feature string
+
+const (
+	FeatureArchives Feature = "archives"
+	FeatureNonces   Feature = "nonces"
+	// FeatureVersioning is required to address object versions.
+	FeatureVersioning Feature = "versioning"
+	// FeatureQuery is required to query objects on the server.
+	FeatureQuery Feature = "query"
+	// FeatureLegalHold is required to place legal holds on objects.
+	FeatureLegalHold Feature = "legal holds"
+	// FeatureStorageClasses is required to choose the storage class of objects.
+	FeatureStorageClasses Feature = "storage classes"
+	// FeatureChangelog is required to list the changes of a bucket.
+	FeatureChangelog Feature = "changelogs"
+	// FeatureNoncePolicies is required to read and write the nonce policy of a bucket.
+	FeatureNoncePolicies Feature = "nonce policies"
+	// FeatureGenerations is required for conditions on object generations.
+	FeatureGenerations Feature = "generation conditions"
+	// FeatureScopedTokens is required to exchange the API key for scoped tokens.
+	FeatureScopedTokens Feature = "scoped tokens"
+)
+
+var featureVersions = map[Feature]int64{
+	FeatureArchives:       1,
+	FeatureNonces:         1,
+	FeatureVersioning:     2,
+	FeatureQuery:          2,
+	FeatureLegalHold:      2,
+	FeatureStorageClasses: 2,
+	FeatureChangelog:      2,
+	FeatureNoncePolicies:  2,
+	FeatureGenerations:    2,
+	FeatureScopedTokens:   2,
+}
+
+// ServerAPIVersion returns the API version advertised by the server in its last response.
+// It returns false if the server has not advertised a version yet.
+func (c *Client) ServerAPIVersion() (int, bool) {
+	v := atomic.LoadInt64(&c.serverVersion)
+	return int(v), v > 0
+}
+
+func (c *Client) recordServerVersion(header http.Header) {
+	v, err := strconv.ParseInt(header.Get(apiVersionHeader), 10, 64)
+	if err != nil || v <= 0 {
+		return
+	}
+	atomic.StoreInt64(&c.serverVersion, v)
+}
+
+// requireFeature fails with ErrIncompatibleServer if the server is too old for the given feature.
+// Servers that did not advertise a version are assumed to support the features of API version 1.
+// Features of later versions address endpoints an older server would interpret as a different operation,
+// e.g. a PUT with a query parameter it does not know as CreateObject. For these, the client fails closed:
+// if the server has not advertised a version yet, it is probed once, and the feature is refused if the
+// version stays unknown.
+func (c *Client) requireFeature(ctx context.Context, f Feature) error {
+	required := featureVersions[f]
+	v, ok := c.ServerAPIVersion()
+	if !ok && required > 1 {
+		if err := c.probeServerVersion(ctx); err != nil {
+			return fmt.Errorf("unable to determine server API version: %w", err)
+		}
+		v, ok = c.ServerAPIVersion()
+		if !ok {
+			return fmt.Errorf("%w: %s require API version %d, server did not advertise a version", ErrIncompatibleServer, f, required)
+		}
+	}
+	if ok && int64(v) < required {
+		return fmt.Errorf("%w: %s require API version %d, server has %d", ErrIncompatibleServer, f, required, v)
+	}
+	return nil
+}
+
+// probeServerVersion sends a minimal request to learn the API version of the server.
+func (c *Client) probeServerVersion(ctx context.Context) error {
+	query := url.Values{}
+	query.Set("max-buckets", "1")
+	_, _, err := c.doReq(ctx, R{
+		op:    OperationListBuckets,
+		query: query,
+	})
+	return err
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const versionIdHeader = "Stor-Version-Id"
+
+type ListObjectVersionsCommand struct {
+	Bucket string
+	Prefix string
+	// KeyMarker and VersionIdMarker continue a listing after the given version,
+	// usually ListObjectVersionsResult.NextKeyMarker and NextVersionIdMarker.
+	KeyMarker       string
+	VersionIdMarker string
+	// MaxKeys limits the results to max versions. Defaults to MaxListKeys if 0.
+	MaxKeys int
+}
+
+type ObjectVersion struct {
+	Key         string    `json:"key"`
+	VersionId   string    `json:"versionId"`
+	IsLatest    bool      `json:"isLatest"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	ETag        string    `json:"etag"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// DeleteMarker reports whether the version marks the object as deleted. Delete markers have no content.
+	DeleteMarker bool `json:"deleteMarker,omitempty"`
+}
+
+type ListObjectVersionsResult struct {
+	IsTruncated         bool             `json:"isTruncated"`
+	NextKeyMarker       string           `json:"nextKeyMarker"`
+	NextVersionIdMarker string           `json:"nextVersionIdMarker"`
+	Versions            []*ObjectVersion `json:"versions"`
+}
+
+// ListObjectVersions lists all versions of the objects in a versioned bucket, ordered by key and from the newest
+// to the oldest version. If the bucket does not exist, the method returns ErrBucketNotFound.
+func (c *Client) ListObjectVersions(ctx context.Context, cmd ListObjectVersionsCommand) (*ListObjectVersionsResult, error) {
+	if err := c.requireFeature(ctx, FeatureVersioning); err != nil {
+		return nil, err
+	}
+	if cmd.MaxKeys < 0 || cmd.MaxKeys > MaxListKeys {
+		return nil, fmt.Errorf("%w: MaxKeys must be between 0 and %d, got %d", ErrInvalidArgument, MaxListKeys, cmd.MaxKeys)
+	}
+	query := url.Values{}
+	query.Set("versions", "")
+	if cmd.Prefix != "" {
+		query.Set("prefix", cmd.Prefix)
+	}
+	if cmd.KeyMarker != "" {
+		query.Set("key-marker", cmd.KeyMarker)
+	}
+	if cmd.VersionIdMarker != "" {
+		query.Set("version-id-marker", cmd.VersionIdMarker)
+	}
+	if cmd.MaxKeys > 0 {
+		query.Set("max-keys", strconv.Itoa(cmd.MaxKeys))
+	}
+	res, body, err := c.doReq(ctx, R{
+		op:    OperationListObjectVersions,
+		path:  cmd.Bucket,
+		query: query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == 404 {
+		return nil, ErrBucketNotFound
+	}
+	if res.StatusCode != 200 {
+		//TODO: map error
+		return nil, fmt.Errorf("unable to list object versions: %v", res.StatusCode)
+	}
+
+	var result ListObjectVersionsResult
+	if err := c.unmarshalResponse(res, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// versionQuery returns the query that addresses a specific version of an object, nil if versionId is empty.
+func (c *Client) versionQuery(ctx context.Context, versionId string) (url.Values, error) {
+	if versionId == "" {
+		return nil, nil
+	}
+	if err := c.requireFeature(ctx, FeatureVersioning); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("version-id", versionId)
+	return query, nil
+}
+
+// RestoreObjectVersion makes a previous version of an object the current version again by copying it onto
+// the same key. The restored version is kept, the copy becomes a new version. If the version cannot be found,
+// the method returns ErrObjectNotFound.
+func (c *Client) RestoreObjectVersion(ctx context.Context, bucket, key, versionId string) (*CreateObjectResult, error) {
+	if versionId == "" {
+		return nil, fmt.Errorf("%w: version id must not be empty", ErrInvalidArgument)
+	}
+	return c.CopyObject(ctx, CopyObjectCommand{
+		Bucket:          bucket,
+		SourceKey:       key,
+		SourceVersionId: versionId,
+		DestKey:         key,
+	})
+}
+
+// ReadObjectAt reads the version of an object that was current at t, i.e. the newest version created at or before t.
+// If the object did not exist at t or was deleted, the method returns ErrObjectNotFound.
+// Clients are expected to read and close the returned ReadObjectResult.
+func (c *Client) ReadObjectAt(ctx context.Context, bucket, key string, t time.Time) (*ReadObjectResult, error) {
+	version, err := c.versionAt(ctx, bucket, key, t)
+	if err != nil {
+		return nil, err
+	}
+	return c.ReadObjectWithOptions(ctx, ReadObjectCommand{Bucket: bucket, Key: key, VersionId: version.VersionId})
+}
+
+// versionAt finds the version of an object that was current at t.
+func (c *Client) versionAt(ctx context.Context, bucket, key string, t time.Time) (*ObjectVersion, error) {
+	cmd := ListObjectVersionsCommand{Bucket: bucket, Prefix: key}
+	for {
+		res, err := c.ListObjectVersions(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range res.Versions {
+			if v.Key < key {
+				continue
+			}
+			if v.Key > key {
+				// versions are ordered by key, there are no more versions of the object
+				return nil, ErrObjectNotFound
+			}
+			if v.CreatedAt.After(t) {
+				continue
+			}
+			if v.DeleteMarker {
+				return nil, ErrObjectNotFound
+			}
+			return v, nil
+		}
+		if !res.IsTruncated {
+			return nil, ErrObjectNotFound
+		}
+		cmd.KeyMarker = res.NextKeyMarker
+		cmd.VersionIdMarker = res.NextVersionIdMarker
+	}
+}
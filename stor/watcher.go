@@ -0,0 +1,224 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ArchiveWatcher polls many pending archives from a single goroutine and notifies
+// the watchers once an archive reached a final state.
+//
+// The polling interval of each archive starts at MinInterval and doubles with every poll up to MaxInterval.
+type ArchiveWatcher struct {
+	client      *Client
+	minInterval time.Duration
+	maxInterval time.Duration
+	pollRetries int
+	mu          sync.Mutex
+	archives    map[watchedArchiveKey]*watchedArchive
+	wake        chan struct{}
+}
+
+type ArchiveWatcherOptions struct {
+	// MinInterval is the initial polling interval. Defaults to 1 second.
+	MinInterval time.Duration
+	// MaxInterval is the maximum polling interval. Defaults to 30 seconds.
+	MaxInterval time.Duration
+	// PollRetries is how often a poll that failed because of the network, a timeout or an unavailable server
+	// is retried before the failure is delivered. Defaults to DefaultPollRetries if 0. If negative, polls are
+	// not retried.
+	PollRetries int
+}
+
+// DefaultPollRetries is the default number of retries of a failed archive poll.
+const DefaultPollRetries = 3
+
+// ArchiveWatchResult is delivered once an archive is complete or failed, or if it could not be polled.
+type ArchiveWatchResult struct {
+	Archive *GetArchiveResult
+	Err     error
+}
+
+// watchedArchiveKey identifies a watched archive. Archive ids are only unique within a bucket.
+type watchedArchiveKey struct {
+	bucket    string
+	archiveId string
+}
+
+type watchedArchive struct {
+	cmd      GetArchiveCommand
+	interval time.Duration
+	next     time.Time
+	channels []chan ArchiveWatchResult
+}
+
+// NewArchiveWatcher creates a new ArchiveWatcher. The watcher does not poll until Run is called.
+func (c *Client) NewArchiveWatcher(opts ArchiveWatcherOptions) *ArchiveWatcher {
+	w := &ArchiveWatcher{
+		client:      c,
+		minInterval: opts.MinInterval,
+		maxInterval: opts.MaxInterval,
+		pollRetries: opts.PollRetries,
+		archives:    make(map[watchedArchiveKey]*watchedArchive),
+		wake:        make(chan struct{}, 1),
+	}
+	if w.minInterval <= 0 {
+		w.minInterval = time.Second
+	}
+	if w.maxInterval <= 0 {
+		w.maxInterval = 30 * time.Second
+	}
+	if w.maxInterval < w.minInterval {
+		w.maxInterval = w.minInterval
+	}
+	if w.pollRetries == 0 {
+		w.pollRetries = DefaultPollRetries
+	} else if w.pollRetries < 0 {
+		w.pollRetries = 0
+	}
+	return w
+}
+
+// Watch adds an archive to the watcher. The returned channel receives exactly one result.
+func (w *ArchiveWatcher) Watch(cmd GetArchiveCommand) <-chan ArchiveWatchResult {
+	ch := make(chan ArchiveWatchResult, 1)
+
+	k := watchedArchiveKey{bucket: cmd.Bucket, archiveId: cmd.ArchiveId}
+	w.mu.Lock()
+	a, ok := w.archives[k]
+	if !ok {
+		a = &watchedArchive{
+			cmd:      cmd,
+			interval: w.minInterval,
+			next:     time.Now(),
+		}
+		w.archives[k] = a
+	}
+	a.channels = append(a.channels, ch)
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return ch
+}
+
+// Run polls the watched archives until ctx is done.
+// When ctx is done, all pending watchers receive the context error.
+func (w *ArchiveWatcher) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.abort(ctx.Err())
+			return ctx.Err()
+		case <-w.wake:
+		case <-timer.C:
+		}
+
+		for _, a := range w.due() {
+			res, err := w.poll(ctx, a.cmd)
+			if ctx.Err() != nil {
+				break
+			}
+			if err != nil || res.State == ArchiveStateComplete || res.State == ArchiveStateFailed {
+				w.deliver(a.cmd, ArchiveWatchResult{Archive: res, Err: err})
+				continue
+			}
+			w.reschedule(a)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(w.untilNext())
+	}
+}
+
+// poll gets the state of an archive, retrying transient failures.
+func (w *ArchiveWatcher) poll(ctx context.Context, cmd GetArchiveCommand) (*GetArchiveResult, error) {
+	var res *GetArchiveResult
+	err := w.client.retry(ctx, OperationGetArchive, cmd.Bucket, w.pollRetries, func() error {
+		var err error
+		res, err = w.client.GetArchive(ctx, cmd)
+		return err
+	})
+	return res, err
+}
+
+// due returns all archives that need to be polled now.
+func (w *ArchiveWatcher) due() []*watchedArchive {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	due := make([]*watchedArchive, 0)
+	for _, a := range w.archives {
+		if !a.next.After(now) {
+			due = append(due, a)
+		}
+	}
+	return due
+}
+
+func (w *ArchiveWatcher) reschedule(a *watchedArchive) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	a.next = time.Now().Add(a.interval)
+	a.interval *= 2
+	if a.interval > w.maxInterval {
+		a.interval = w.maxInterval
+	}
+}
+
+// untilNext returns the duration until the next archive needs to be polled.
+func (w *ArchiveWatcher) untilNext() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	d := w.maxInterval
+	now := time.Now()
+	for _, a := range w.archives {
+		if until := a.next.Sub(now); until < d {
+			d = until
+		}
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+func (w *ArchiveWatcher) deliver(cmd GetArchiveCommand, result ArchiveWatchResult) {
+	k := watchedArchiveKey{bucket: cmd.Bucket, archiveId: cmd.ArchiveId}
+	w.mu.Lock()
+	a, ok := w.archives[k]
+	delete(w.archives, k)
+	w.mu.Unlock()
+	if !ok {
+		return
+	}
+	for _, ch := range a.channels {
+		ch <- result
+	}
+}
+
+func (w *ArchiveWatcher) abort(err error) {
+	w.mu.Lock()
+	archives := w.archives
+	w.archives = make(map[watchedArchiveKey]*watchedArchive)
+	w.mu.Unlock()
+	for _, a := range archives {
+		for _, ch := range a.channels {
+			ch <- ArchiveWatchResult{Err: err}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package stor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+type ObjectWriterOptions struct {
+	ContentType string
+	// Metadata is user-defined metadata that is stored with the object.
+	Metadata map[string]string
+	// ContentEncoding is the encoding of the written data, e.g. "gzip" for pre-compressed assets.
+	ContentEncoding string
+	// PartSize is the size of the parts once the writer switches to a multipart upload.
+	// Defaults to the minimum part size of the server.
+	PartSize int64
+	// LeavePartsOnError keeps the uploaded parts of a failed multipart upload instead of aborting the upload.
+	// The id of the upload is returned in a *MultipartUploadError.
+	LeavePartsOnError bool
+}
+
+// ObjectWriter is an io.WriteCloser that uploads everything written to it as an object.
+// Small payloads are uploaded with a single request on Close. Once more than PartSize bytes have been written,
+// the writer switches to a multipart upload and uploads the data part by part.
+//
+// The object is only created once Close returns without an error. If a write fails, the multipart upload is aborted
+// and all further calls return the error.
+type ObjectWriter struct {
+	client   *Client
+	ctx      context.Context
+	bucket   string
+	key      string
+	opts     ObjectWriterOptions
+	buf      bytes.Buffer
+	uploadId string
+	parts    []PartReference
+	etag     string
+	err      error
+	closed   bool
+}
+
+// NewObjectWriter creates a writer for the object at key. Nothing is sent to the server until enough data
+// for a part has been written or the writer is closed.
+func (c *Client) NewObjectWriter(ctx context.Context, bucket, key string, opts ObjectWriterOptions) *ObjectWriter {
+	if opts.PartSize <= 0 {
+		opts.PartSize = c.limits.MinPartSize
+	}
+	return &ObjectWriter{
+		client: c,
+		ctx:    ctx,
+		bucket: bucket,
+		key:    key,
+		opts:   opts,
+	}
+}
+
+func (w *ObjectWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, fmt.Errorf("write to closed object writer")
+	}
+	n, _ := w.buf.Write(p)
+	for int64(w.buf.Len()) > w.opts.PartSize {
+		if err := w.uploadPart(w.opts.PartSize); err != nil {
+			w.fail(err)
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Close uploads the remaining data and creates the object.
+func (w *ObjectWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if w.uploadId == "" {
+		res, err := w.client.CreateObject(w.ctx, CreateObjectCommand{
+			Bucket:          w.bucket,
+			Key:             w.key,
+			ContentType:     w.opts.ContentType,
+			Data:            bytes.NewReader(w.buf.Bytes()),
+			Metadata:        w.opts.Metadata,
+			ContentEncoding: w.opts.ContentEncoding,
+		})
+		if err != nil {
+			w.err = err
+			return err
+		}
+		w.etag = res.ETag
+		return nil
+	}
+
+	if w.buf.Len() > 0 {
+		if err := w.uploadPart(int64(w.buf.Len())); err != nil {
+			w.fail(err)
+			return err
+		}
+	}
+	res, err := w.client.CompleteMultipartUpload(w.ctx, CompleteMultipartUploadCommand{
+		Bucket:   w.bucket,
+		Key:      w.key,
+		UploadId: w.uploadId,
+		Parts:    w.parts,
+	})
+	if err != nil {
+		w.fail(err)
+		return err
+	}
+	w.etag = res.ETag
+	return nil
+}
+
+// ETag returns the ETag of the created object. It is empty until Close returned successfully.
+func (w *ObjectWriter) ETag() string {
+	return w.etag
+}
+
+// uploadPart uploads the next size bytes of the buffer as a part, starting the multipart upload if necessary.
+func (w *ObjectWriter) uploadPart(size int64) error {
+	if w.uploadId == "" {
+		res, err := w.client.CreateMultipartUpload(w.ctx, CreateMultipartUploadCommand{
+			Bucket:          w.bucket,
+			Key:             w.key,
+			ContentType:     w.opts.ContentType,
+			Metadata:        w.opts.Metadata,
+			ContentEncoding: w.opts.ContentEncoding,
+		})
+		if err != nil {
+			return err
+		}
+		w.uploadId = res.UploadId
+	}
+	partNumber := len(w.parts) + 1
+	res, err := w.client.UploadPart(w.ctx, UploadPartCommand{
+		Bucket:        w.bucket,
+		Key:           w.key,
+		UploadId:      w.uploadId,
+		PartNumber:    partNumber,
+		Data:          bytes.NewReader(w.buf.Next(int(size))),
+		ContentLength: size,
+	})
+	if err != nil {
+		return err
+	}
+	w.parts = append(w.parts, PartReference{ETag: res.ETag, PartNumber: partNumber})
+	return nil
+}
+
+// fail records err and aborts the multipart upload, if one was started.
+func (w *ObjectWriter) fail(err error) {
+	w.buf.Reset()
+	if w.uploadId == "" {
+		w.err = err
+		return
+	}
+	w.err = w.client.failMultipartUpload(w.ctx, w.bucket, w.key, w.uploadId, w.opts.LeavePartsOnError, err)
+}
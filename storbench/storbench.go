@@ -0,0 +1,332 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package storbench drives read, write and list workloads against a STOR server and reports throughput
+// and latency percentiles, e.g. for capacity planning.
+package storbench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cfichtmueller/stor-go-client/stor"
+)
+
+// Operation is a benchmarked operation.
+type Operation string
+
+const (
+	OperationRead  Operation = "read"
+	OperationWrite Operation = "write"
+	OperationList  Operation = "list"
+)
+
+// SizeClass is an object size that is written with the given relative weight.
+type SizeClass struct {
+	Size   int64
+	Weight int
+}
+
+// Mix is the relative weight of each operation in the workload.
+type Mix struct {
+	Read  int
+	Write int
+	List  int
+}
+
+type Config struct {
+	// Bucket is the bucket the benchmark runs in. It must exist.
+	Bucket string
+	// Prefix is the key prefix of all objects written by the benchmark. Defaults to "storbench/".
+	Prefix string
+	// Duration is the time the workload runs. Defaults to 30 seconds.
+	Duration time.Duration
+	// Concurrency is the number of concurrent workers. Defaults to 8.
+	Concurrency int
+	// Mix is the operation mix. Defaults to 70% reads, 20% writes and 10% lists.
+	Mix Mix
+	// Sizes is the distribution of written object sizes. Defaults to 64 KB objects.
+	Sizes []SizeClass
+	// Seed is the number of objects written before the benchmark starts, so that reads find objects.
+	// Defaults to 16.
+	Seed int
+	// Cleanup deletes all objects written by the benchmark once it is done.
+	Cleanup bool
+}
+
+func (c *Config) setDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "storbench/"
+	}
+	if c.Duration <= 0 {
+		c.Duration = 30 * time.Second
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	if c.Mix.Read <= 0 && c.Mix.Write <= 0 && c.Mix.List <= 0 {
+		c.Mix = Mix{Read: 70, Write: 20, List: 10}
+	}
+	if len(c.Sizes) == 0 {
+		c.Sizes = []SizeClass{{Size: 64 << 10, Weight: 1}}
+	}
+	if c.Seed <= 0 {
+		c.Seed = 16
+	}
+}
+
+// OperationStats are the results of a single operation.
+type OperationStats struct {
+	Operation Operation
+	Count     int
+	Errors    int
+	// Bytes is the number of transferred payload bytes.
+	Bytes int64
+	// OpsPerSecond and BytesPerSecond are measured over the duration of the benchmark.
+	OpsPerSecond   float64
+	BytesPerSecond float64
+	P50            time.Duration
+	P90            time.Duration
+	P99            time.Duration
+	Max            time.Duration
+}
+
+type Report struct {
+	Duration   time.Duration
+	Operations []OperationStats
+}
+
+// Print writes the report as a table.
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "op\tcount\terrors\tops/s\tMB/s\tp50\tp90\tp99\tmax\t\n")
+	for _, s := range r.Operations {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.2f\t%v\t%v\t%v\t%v\t\n",
+			s.Operation, s.Count, s.Errors, s.OpsPerSecond, s.BytesPerSecond/(1<<20),
+			s.P50.Round(time.Microsecond), s.P90.Round(time.Microsecond), s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+	}
+	return tw.Flush()
+}
+
+type sample struct {
+	latency time.Duration
+	bytes   int64
+	err     error
+}
+
+type benchmark struct {
+	client  *stor.Client
+	cfg     Config
+	payload []byte
+
+	mu      sync.Mutex
+	keys    []string
+	samples map[Operation][]sample
+}
+
+// Run seeds the bucket, runs the workload and reports the results. Failed operations are counted as errors
+// and do not stop the benchmark.
+func Run(ctx context.Context, client *stor.Client, cfg Config) (*Report, error) {
+	cfg.setDefaults()
+	maxSize := int64(0)
+	for _, s := range cfg.Sizes {
+		if s.Size > maxSize {
+			maxSize = s.Size
+		}
+	}
+	b := &benchmark{
+		client:  client,
+		cfg:     cfg,
+		payload: make([]byte, maxSize),
+		samples: make(map[Operation][]sample),
+	}
+	rand.New(rand.NewSource(1)).Read(b.payload)
+
+	if cfg.Cleanup {
+		defer b.cleanup()
+	}
+	seed := rand.New(rand.NewSource(0))
+	for i := 0; i < cfg.Seed; i++ {
+		if s := b.write(ctx, seed, fmt.Sprintf("seed-%d", i)); s.err != nil {
+			return nil, fmt.Errorf("unable to seed bucket: %w", s.err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			b.work(ctx, worker)
+		}(i)
+	}
+	wg.Wait()
+	return b.report(time.Since(start)), nil
+}
+
+func (b *benchmark) work(ctx context.Context, worker int) {
+	rnd := rand.New(rand.NewSource(int64(worker) + 1))
+	total := b.cfg.Mix.Read + b.cfg.Mix.Write + b.cfg.Mix.List
+	for n := 0; ctx.Err() == nil; n++ {
+		var op Operation
+		var s sample
+		switch pick := rnd.Intn(total); {
+		case pick < b.cfg.Mix.Read:
+			op, s = OperationRead, b.read(ctx, rnd)
+		case pick < b.cfg.Mix.Read+b.cfg.Mix.Write:
+			op, s = OperationWrite, b.write(ctx, rnd, fmt.Sprintf("w-%d-%d", worker, n))
+		default:
+			op, s = OperationList, b.list(ctx)
+		}
+		if ctx.Err() != nil && errors.Is(s.err, context.DeadlineExceeded) {
+			// operations interrupted by the end of the benchmark are not counted
+			return
+		}
+		b.mu.Lock()
+		b.samples[op] = append(b.samples[op], s)
+		b.mu.Unlock()
+	}
+}
+
+func (b *benchmark) write(ctx context.Context, rnd *rand.Rand, name string) sample {
+	size := b.size(rnd)
+	key := b.cfg.Prefix + name
+	start := time.Now()
+	_, err := b.client.CreateObject(ctx, stor.CreateObjectCommand{
+		Bucket:        b.cfg.Bucket,
+		Key:           key,
+		ContentType:   "application/octet-stream",
+		Data:          bytes.NewReader(b.payload[:size]),
+		ContentLength: size,
+	})
+	s := sample{latency: time.Since(start), err: err}
+	if err == nil {
+		s.bytes = size
+		b.mu.Lock()
+		b.keys = append(b.keys, key)
+		b.mu.Unlock()
+	}
+	return s
+}
+
+func (b *benchmark) read(ctx context.Context, rnd *rand.Rand) sample {
+	b.mu.Lock()
+	key := b.keys[rnd.Intn(len(b.keys))]
+	b.mu.Unlock()
+	start := time.Now()
+	res, err := b.client.ReadObjectWithOptions(ctx, stor.ReadObjectCommand{
+		Bucket: b.cfg.Bucket,
+		Key:    key,
+	})
+	if err != nil {
+		return sample{latency: time.Since(start), err: err}
+	}
+	n, err := io.Copy(io.Discard, res)
+	res.Close()
+	return sample{latency: time.Since(start), bytes: n, err: err}
+}
+
+func (b *benchmark) list(ctx context.Context) sample {
+	start := time.Now()
+	_, err := b.client.ListObjects(ctx, stor.ListObjectsCommand{
+		Bucket:  b.cfg.Bucket,
+		Prefix:  b.cfg.Prefix,
+		MaxKeys: 100,
+	})
+	return sample{latency: time.Since(start), err: err}
+}
+
+// size picks an object size from the size distribution.
+func (b *benchmark) size(rnd *rand.Rand) int64 {
+	total := 0
+	for _, s := range b.cfg.Sizes {
+		total += s.Weight
+	}
+	if total <= 0 {
+		return b.cfg.Sizes[0].Size
+	}
+	pick := rnd.Intn(total)
+	for _, s := range b.cfg.Sizes {
+		if pick < s.Weight {
+			return s.Size
+		}
+		pick -= s.Weight
+	}
+	return b.cfg.Sizes[len(b.cfg.Sizes)-1].Size
+}
+
+func (b *benchmark) cleanup() {
+	refs := make([]stor.ObjectReference, 0, len(b.keys))
+	for _, key := range b.keys {
+		refs = append(refs, stor.ObjectReference{Key: key})
+	}
+	for start := 0; start < len(refs); start += 1000 {
+		end := start + 1000
+		if end > len(refs) {
+			end = len(refs)
+		}
+		if _, err := b.client.DeleteObjects(context.Background(), stor.DeleteObjectsCommand{
+			Bucket:  b.cfg.Bucket,
+			Objects: refs[start:end],
+		}); err != nil {
+			return
+		}
+	}
+}
+
+func (b *benchmark) report(elapsed time.Duration) *Report {
+	r := &Report{Duration: elapsed}
+	for _, op := range []Operation{OperationRead, OperationWrite, OperationList} {
+		samples := b.samples[op]
+		if len(samples) == 0 {
+			continue
+		}
+		stats := OperationStats{Operation: op}
+		latencies := make([]time.Duration, 0, len(samples))
+		for _, s := range samples {
+			stats.Count++
+			if s.err != nil {
+				stats.Errors++
+				continue
+			}
+			stats.Bytes += s.bytes
+			latencies = append(latencies, s.latency)
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stats.P50 = percentile(latencies, 0.50)
+		stats.P90 = percentile(latencies, 0.90)
+		stats.P99 = percentile(latencies, 0.99)
+		stats.Max = percentile(latencies, 1)
+		stats.OpsPerSecond = float64(stats.Count) / elapsed.Seconds()
+		stats.BytesPerSecond = float64(stats.Bytes) / elapsed.Seconds()
+		r.Operations = append(r.Operations, stats)
+	}
+	return r
+}
+
+// percentile returns the latency at p of the sorted latencies, using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package storhttp provides HTTP handlers built on the STOR client.
+package storhttp
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cfichtmueller/stor-go-client/stor"
+)
+
+type listing struct {
+	Bucket         string         `json:"bucket"`
+	Prefix         string         `json:"prefix"`
+	CommonPrefixes []string       `json:"commonPrefixes"`
+	Objects        []*stor.Object `json:"objects"`
+	Next           string         `json:"next,omitempty"`
+}
+
+var listingTemplate = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Bucket}}/{{.Prefix}}</title></head>
+<body>
+<h1>{{.Bucket}}/{{.Prefix}}</h1>
+<table>
+<tr><th>Key</th><th>Content Type</th><th>Size</th><th>Created</th></tr>
+{{range .CommonPrefixes}}<tr><td><a href="?prefix={{.}}">{{.}}</a></td><td></td><td></td><td></td></tr>
+{{end}}{{range .Objects}}<tr><td>{{.Key}}</td><td>{{.ContentType}}</td><td>{{.Size}}</td><td>{{.CreatedAt}}</td></tr>
+{{end}}</table>
+{{if .Next}}<p><a href="{{.Next}}">Next page</a></p>{{end}}
+</body>
+</html>
+`))
+
+// BrowserHandler returns a handler that renders a directory listing of a bucket.
+//
+// The bucket is taken from the first element of the request path, the prefix and
+// pagination cursor from the "prefix" and "start-after" query parameters.
+// The listing is rendered as JSON if the request has "format=json" or accepts application/json, as HTML otherwise.
+// The handler is meant for internal tooling and does not perform any authorization.
+func BrowserHandler(client *stor.Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket := strings.Trim(r.URL.Path, "/")
+		if bucket == "" || strings.Contains(bucket, "/") {
+			http.Error(w, "bucket required", http.StatusNotFound)
+			return
+		}
+		query := r.URL.Query()
+		maxKeys := 0
+		if s := query.Get("max-keys"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				http.Error(w, "invalid max-keys", http.StatusBadRequest)
+				return
+			}
+			maxKeys = n
+		}
+
+		page, err := client.ListObjects(r.Context(), stor.ListObjectsCommand{
+			Bucket:     bucket,
+			Prefix:     query.Get("prefix"),
+			StartAfter: query.Get("start-after"),
+			Delimiter:  "/",
+			MaxKeys:    maxKeys,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		l := listing{
+			Bucket:         bucket,
+			Prefix:         query.Get("prefix"),
+			CommonPrefixes: page.CommonPrefixes,
+			Objects:        page.Objects,
+		}
+		if page.IsTruncated {
+			next := url.Values{}
+			for k, v := range query {
+				next[k] = v
+			}
+			next.Set("start-after", lastKey(page))
+			l.Next = "?" + next.Encode()
+		}
+
+		if query.Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(l)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = listingTemplate.Execute(w, l)
+	})
+}
+
+// lastKey returns the last key or common prefix of the page, whichever sorts last.
+func lastKey(page *stor.ListObjectsResult) string {
+	last := ""
+	if len(page.Objects) > 0 {
+		last = page.Objects[len(page.Objects)-1].Key
+	}
+	if len(page.CommonPrefixes) > 0 {
+		if p := page.CommonPrefixes[len(page.CommonPrefixes)-1]; p > last {
+			last = p
+		}
+	}
+	return last
+}